@@ -3,16 +3,28 @@ package config
 import (
 	"bufio"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type FeedConfig struct {
-	URL      string
-	Category string
+	URL       string
+	Category  string
+	Interval  time.Duration
+	Timeout   time.Duration
+	Enabled   bool
+	Title     string
+	UserAgent string
+	FullText  bool
 }
 
 type Config struct {
-	Feeds []FeedConfig
+	Feeds     []FeedConfig
+	Workers   int
+	PerHost   int
+	DeadAfter time.Duration
+	Timezone  string
 }
 
 func LoadConfig(filename string) (Config, error) {
@@ -25,7 +37,7 @@ func LoadConfig(filename string) (Config, error) {
 	var cfg Config
 	scanner := bufio.NewScanner(file)
 	var mode string
-	var feed FeedConfig
+	feed := FeedConfig{Enabled: true}
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -37,16 +49,43 @@ func LoadConfig(filename string) (Config, error) {
 		case "feed:":
 			if feed.URL != "" {
 				cfg.Feeds = append(cfg.Feeds, feed)
-				feed = FeedConfig{}
+				feed = FeedConfig{Enabled: true}
 			}
 			mode = "feed"
 
+		case "settings:":
+			mode = "settings"
+
 		default:
 			if mode == "feed" {
 				if strings.HasPrefix(line, "url:") {
 					feed.URL = strings.TrimSpace(strings.TrimPrefix(line, "url:"))
 				} else if strings.HasPrefix(line, "category:") {
 					feed.Category = strings.TrimSpace(strings.TrimPrefix(line, "category:"))
+				} else if strings.HasPrefix(line, "interval:") {
+					feed.Interval = parseDuration(strings.TrimSpace(strings.TrimPrefix(line, "interval:")), time.Minute)
+				} else if strings.HasPrefix(line, "timeout:") {
+					feed.Timeout = parseDuration(strings.TrimSpace(strings.TrimPrefix(line, "timeout:")), time.Second)
+				} else if strings.HasPrefix(line, "enabled:") {
+					if enabled, err := strconv.ParseBool(strings.TrimSpace(strings.TrimPrefix(line, "enabled:"))); err == nil {
+						feed.Enabled = enabled
+					}
+				} else if strings.HasPrefix(line, "title:") {
+					feed.Title = strings.TrimSpace(strings.TrimPrefix(line, "title:"))
+				} else if strings.HasPrefix(line, "user_agent:") {
+					feed.UserAgent = strings.TrimSpace(strings.TrimPrefix(line, "user_agent:"))
+				} else if strings.HasPrefix(line, "fulltext:") {
+					feed.FullText, _ = strconv.ParseBool(strings.TrimSpace(strings.TrimPrefix(line, "fulltext:")))
+				}
+			} else if mode == "settings" {
+				if strings.HasPrefix(line, "workers:") {
+					cfg.Workers, _ = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "workers:")))
+				} else if strings.HasPrefix(line, "per_host:") {
+					cfg.PerHost, _ = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "per_host:")))
+				} else if strings.HasPrefix(line, "dead_after:") {
+					cfg.DeadAfter = parseDuration(strings.TrimSpace(strings.TrimPrefix(line, "dead_after:")), 24*time.Hour)
+				} else if strings.HasPrefix(line, "timezone:") {
+					cfg.Timezone = strings.TrimSpace(strings.TrimPrefix(line, "timezone:"))
 				}
 			}
 		}
@@ -58,3 +97,16 @@ func LoadConfig(filename string) (Config, error) {
 
 	return cfg, scanner.Err()
 }
+
+// parseDuration accepts Go durations ("90m", "2h") or a bare number counted
+// in unit. Invalid values yield 0.
+func parseDuration(value string, unit time.Duration) time.Duration {
+	if n, err := strconv.Atoi(value); err == nil {
+		return time.Duration(n) * unit
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0
+	}
+	return d
+}
@@ -1,18 +1,15 @@
 package fetcher
 
 import (
-	"encoding/xml"
-	"html/template"
-	"log"
+	"html"
 	"news-aggregator/models"
 	"news-aggregator/utils"
-	"time"
 )
 
 type AtomFeed struct {
 	ID           string         `xml:"id"`
 	Title        AtomText       `xml:"title"`
-	Updated      time.Time      `xml:"updated"`
+	Updated      string         `xml:"updated"`
 	Authors      []AtomPerson   `xml:"author,omitempty"`
 	Links        []AtomLink     `xml:"link"`
 	Categories   []AtomCategory `xml:"category,omitempty"`
@@ -26,23 +23,25 @@ type AtomFeed struct {
 }
 
 type AtomEntry struct {
-	ID           string         `xml:"id"`
-	Title        AtomText       `xml:"title"`
-	Updated      string         `xml:"updated"`
-	Authors      []AtomPerson   `xml:"author,omitempty"`
+	ID      string       `xml:"id"`
+	Title   AtomText     `xml:"title"`
+	Updated string       `xml:"updated"`
+	Authors []AtomPerson `xml:"author,omitempty"`
+	Media
 	Content      *AtomContent   `xml:"content,omitempty"`
 	Links        []AtomLink     `xml:"link"`
 	Summary      *AtomText      `xml:"summary,omitempty"`
 	Categories   []AtomCategory `xml:"category,omitempty"`
 	Contributors []AtomPerson   `xml:"contributor,omitempty"`
-	Published    *time.Time     `xml:"published,omitempty"`
+	Published    string         `xml:"published,omitempty"`
 	Rights       *AtomText      `xml:"rights,omitempty"`
 	Source       *AtomFeed      `xml:"http://www.w3.org/2005/Atom source,omitempty"`
 }
 
 type AtomText struct {
-	Type string `xml:"type,attr,omitempty"`
-	Text string `xml:",chardata"`
+	Type     string `xml:"type,attr,omitempty"`
+	Text     string `xml:",chardata"`
+	InnerXML string `xml:",innerxml"`
 }
 
 type AtomPerson struct {
@@ -80,9 +79,14 @@ type AtomContent struct {
 }
 
 func ParseAtom(data []byte, category string) []models.NewsItem {
+	items, _ := parseAtom(data, category)
+	return items
+}
+
+func parseAtom(data []byte, category string) ([]models.NewsItem, error) {
 	var atom AtomFeed
-	if err := xml.Unmarshal(data, &atom); err != nil {
-		return nil
+	if err := newXMLDecoder(data).Decode(&atom); err != nil {
+		return nil, err
 	}
 
 	var channelLink string
@@ -96,50 +100,61 @@ func ParseAtom(data []byte, category string) []models.NewsItem {
 	var newsItems []models.NewsItem
 	for _, entry := range atom.Entries {
 		var itemLink string
+		var enclosures []mediaFile
 		for _, link := range entry.Links {
-			if link.Rel == "alternate" || link.Rel == "" {
+			if (link.Rel == "alternate" || link.Rel == "") && itemLink == "" {
 				itemLink = link.Href
-				break
+			} else if link.Rel == "enclosure" {
+				enclosures = append(enclosures, mediaFile{URL: link.Href, Type: link.Type})
 			}
 		}
 
-		var dateStr string
-		if entry.Published != nil {
-			dateStr = entry.Published.Format(time.RFC3339)
-		} else if entry.Updated != "" {
-			dateStr = entry.Updated
-		}
-
-		if dateStr == "" {
-			log.Printf("Failed to parse date '' for entry '%s': empty date string", entry.Title.Text)
-			continue
-		}
-
-		pubTime, err := utils.FormatDate(dateStr)
-		if err != nil {
-			log.Printf("Failed to parse date '%s' for entry '%s': %v", dateStr, entry.Title.Text, err)
-			continue
-		}
+		pubTime, approximate := itemDate(itemKey(entry.ID, itemLink, entry.Title.Text), entry.Title.Text,
+			[]string{entry.Published, entry.Updated},
+			[]string{atom.Updated})
 
-		var cleanedDescription string
+		var markup string
 		if entry.Content != nil {
-			cleanedDescription = utils.StripHTMLTags(entry.Content.Text)
+			markup = atomMarkup(entry.Content.Type, entry.Content.Text, entry.Content.InnerXML)
 		} else if entry.Summary != nil {
-			cleanedDescription = utils.StripHTMLTags(entry.Summary.Text)
+			markup = atomMarkup(entry.Summary.Type, entry.Summary.Text, entry.Summary.InnerXML)
 		}
 
+		base := itemLink
+		if base == "" {
+			base = channelLink
+		}
+		mediaURL, mediaType, thumbnail := itemMedia(enclosures, entry.Media, markup, base)
+
 		newsItems = append(newsItems, models.NewsItem{
-			Title:        entry.Title.Text,
-			Description:  template.HTML(cleanedDescription),
-			ChannelLink:  channelLink,
-			PubDate:      pubTime,
-			Content:      template.HTML(cleanedDescription),
-			ItemLink:     itemLink,
-			ChannelTitle: atom.Title.Text,
-			Category:     category,
-			Favicon:      utils.GetFaviconURL(channelLink),
+			Title:           entry.Title.Text,
+			Description:     utils.PlainText(markup),
+			ChannelLink:     channelLink,
+			PubDate:         pubTime,
+			DateApproximate: approximate,
+			Content:         utils.SanitizeHTML(markup, base),
+			MediaURL:        mediaURL,
+			MediaType:       mediaType,
+			Thumbnail:       thumbnail,
+			Guid:            entry.ID,
+			ItemLink:        itemLink,
+			ChannelTitle:    atom.Title.Text,
+			Category:        category,
+			Favicon:         utils.GetFaviconURL(channelLink),
 		})
 	}
 
-	return newsItems
+	return newsItems, nil
+}
+
+// atomMarkup returns the HTML of an Atom text construct. Plain text is
+// escaped; a missing type is treated as HTML since many feeds leave it out.
+func atomMarkup(contentType, text, innerXML string) string {
+	switch contentType {
+	case "xhtml":
+		return innerXML
+	case "text":
+		return html.EscapeString(text)
+	}
+	return text
 }
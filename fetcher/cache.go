@@ -0,0 +1,61 @@
+package fetcher
+
+import (
+	"net/http"
+	"news-aggregator/models"
+	"sync"
+)
+
+type cacheEntry struct {
+	ETag         string
+	LastModified string
+	Items        []models.NewsItem
+}
+
+var (
+	feedCache = make(map[string]cacheEntry)
+	cacheMu   sync.Mutex
+)
+
+// setConditionalHeaders adds If-None-Match / If-Modified-Since for feeds
+// whose validators were remembered on a previous fetch.
+func setConditionalHeaders(req *http.Request, feedURL string) {
+	cacheMu.Lock()
+	entry, ok := feedCache[feedURL]
+	cacheMu.Unlock()
+	if !ok {
+		return
+	}
+	if entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+	if entry.LastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+}
+
+func cachedItems(feedURL string) ([]models.NewsItem, bool) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	entry, ok := feedCache[feedURL]
+	return entry.Items, ok
+}
+
+// rememberResponse stores the validators of resp together with the items
+// parsed from its body. Responses without validators are not cached.
+func rememberResponse(feedURL string, resp *http.Response, items []models.NewsItem) {
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if etag == "" && lastModified == "" {
+		delete(feedCache, feedURL)
+		return
+	}
+	feedCache[feedURL] = cacheEntry{
+		ETag:         etag,
+		LastModified: lastModified,
+		Items:        items,
+	}
+}
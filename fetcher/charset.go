@@ -0,0 +1,188 @@
+package fetcher
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// charmap maps the upper half (0x80-0xFF) of a single-byte encoding to
+// Unicode. The lower half is ASCII.
+type charmap [128]rune
+
+var (
+	windows1251 = newCharmap(func(b byte) rune {
+		switch {
+		case b >= 0xC0:
+			return 0x0410 + rune(b-0xC0)
+		case b >= 0x80:
+			return []rune("ЂЃ‚ѓ„…†‡€‰Љ‹ЊЌЋЏђ‘’“”•–—�™љ›њќћџ ЎўЈ¤Ґ¦§Ё©Є«¬­®Ї°±Ііґµ¶·ё№є»јЅѕї")[b-0x80]
+		}
+		return rune(b)
+	})
+	koi8r = newCharmap(func(b byte) rune {
+		switch {
+		case b >= 0xE0:
+			return unicode.ToUpper(koi8Letters[b-0xE0])
+		case b >= 0xC0:
+			return koi8Letters[b-0xC0]
+		case b >= 0x80:
+			return []rune("─│┌┐└┘├┤┬┴┼▀▄█▌▐░▒▓⌠■∙√≈≤≥ ⌡°²·÷═║╒ё╓╔╕╖╗╘╙╚╛╜╝╞╟╠╡Ё╢╣╤╥╦╧╨╩╪╫╬©")[b-0x80]
+		}
+		return rune(b)
+	})
+	koi8u = newCharmap(func(b byte) rune {
+		switch b {
+		case 0xA4:
+			return 'є'
+		case 0xA6:
+			return 'і'
+		case 0xA7:
+			return 'ї'
+		case 0xAD:
+			return 'ґ'
+		case 0xB4:
+			return 'Є'
+		case 0xB6:
+			return 'І'
+		case 0xB7:
+			return 'Ї'
+		case 0xBD:
+			return 'Ґ'
+		}
+		return koi8r[b-0x80]
+	})
+	iso88595 = newCharmap(func(b byte) rune {
+		switch {
+		case b == 0xAD:
+			return '­'
+		case b == 0xF0:
+			return '№'
+		case b == 0xFD:
+			return '§'
+		case b >= 0xA1:
+			return 0x0360 + rune(b)
+		}
+		return rune(b)
+	})
+	// ISO-8859-1 labels are decoded as windows-1252, like browsers do.
+	windows1252 = newCharmap(func(b byte) rune {
+		if b >= 0xA0 {
+			return rune(b)
+		}
+		if r := []rune("€\u0081‚ƒ„…†‡ˆ‰Š‹Œ\u008DŽ\u008F\u0090‘’“”•–—˜™š›œ\u009DžŸ")[b-0x80]; r != 0 {
+			return r
+		}
+		return rune(b)
+	})
+)
+
+// koi8Letters is the KOI8 order of the lower-case Cyrillic alphabet; the
+// upper-case letters follow in the same order 0x20 higher.
+var koi8Letters = []rune("юабцдефгхийклмнопярстужвьызшэщчъ")
+
+func newCharmap(decode func(b byte) rune) *charmap {
+	var cm charmap
+	for i := range cm {
+		cm[i] = decode(byte(0x80 + i))
+	}
+	return &cm
+}
+
+var charmaps = map[string]*charmap{
+	"windows-1251": windows1251,
+	"cp1251":       windows1251,
+	"x-cp1251":     windows1251,
+	"koi8-r":       koi8r,
+	"koi8r":        koi8r,
+	"koi8":         koi8r,
+	"koi8-u":       koi8u,
+	"iso-8859-5":   iso88595,
+	"iso8859-5":    iso88595,
+	"cyrillic":     iso88595,
+	"windows-1252": windows1252,
+	"cp1252":       windows1252,
+	"iso-8859-1":   windows1252,
+	"iso8859-1":    windows1252,
+	"iso_8859-1":   windows1252,
+	"latin1":       windows1252,
+	"l1":           windows1252,
+	"us-ascii":     windows1252,
+	"ascii":        windows1252,
+}
+
+func isUTF8(label string) bool {
+	label = strings.ToLower(strings.TrimSpace(label))
+	return label == "" || label == "utf-8" || label == "utf8"
+}
+
+// charsetReader is used as xml.Decoder.CharsetReader.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	if isUTF8(label) {
+		return input, nil
+	}
+	cm, ok := charmaps[strings.ToLower(strings.TrimSpace(label))]
+	if !ok {
+		return nil, fmt.Errorf("unsupported charset %q", label)
+	}
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(cm.decode(data)), nil
+}
+
+func (cm *charmap) decode(data []byte) []byte {
+	out := make([]byte, 0, len(data)+len(data)/2)
+	for _, b := range data {
+		if b < 0x80 {
+			out = append(out, b)
+			continue
+		}
+		out = utf8.AppendRune(out, cm[b-0x80])
+	}
+	return out
+}
+
+// newXMLDecoder returns a decoder that understands the encodings above.
+func newXMLDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charsetReader
+	return decoder
+}
+
+// decodeBody converts body to UTF-8 using the charset of the Content-Type
+// header. XML documents that declare their own encoding are left alone and
+// decoded by charsetReader, since the declaration is written by whatever
+// produced the bytes while the header is often a server default.
+func decodeBody(contentType string, body []byte) []byte {
+	body = bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))
+
+	_, params, _ := mime.ParseMediaType(contentType)
+	label := params["charset"]
+	if isUTF8(label) || declaresEncoding(body) {
+		return body
+	}
+	cm, ok := charmaps[strings.ToLower(strings.TrimSpace(label))]
+	if !ok {
+		return body
+	}
+	return cm.decode(body)
+}
+
+func declaresEncoding(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	if !bytes.HasPrefix(trimmed, []byte("<?xml")) {
+		return false
+	}
+	end := bytes.Index(trimmed, []byte("?>"))
+	if end < 0 {
+		return false
+	}
+	return bytes.Contains(trimmed[:end], []byte("encoding"))
+}
@@ -0,0 +1,191 @@
+package fetcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	formatRSS     = "rss"
+	formatAtom    = "atom"
+	formatJSON    = "json"
+	formatHTML    = "html"
+	formatUnknown = ""
+)
+
+var (
+	discoveredURLs = make(map[string]string)
+	discoveredMu   sync.Mutex
+)
+
+// detectFormat picks the feed format from the Content-Type header when it
+// names a feed type, otherwise from the root element of the body, and
+// finally from a JSON sniff. Generic types such as text/xml or text/html
+// only decide the format when the body gives no answer.
+func detectFormat(contentType string, body []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/rss+xml", "application/rdf+xml":
+		return formatRSS
+	case "application/atom+xml":
+		return formatAtom
+	case "application/feed+json":
+		return formatJSON
+	}
+
+	body = bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(body)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		if isJSONFeed(trimmed) {
+			return formatJSON
+		}
+		return formatUnknown
+	}
+
+	switch rootElement(trimmed) {
+	case "rss", "rdf":
+		return formatRSS
+	case "feed":
+		return formatAtom
+	case "html":
+		return formatHTML
+	}
+
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		return formatHTML
+	}
+	return formatUnknown
+}
+
+// rootElement returns the lower-cased local name of the first element in
+// data, or "" if there is none.
+func rootElement(data []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+	// The root name is ASCII, so the declared encoding doesn't matter here.
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		if elem, ok := tok.(xml.StartElement); ok {
+			return strings.ToLower(elem.Name.Local)
+		}
+	}
+}
+
+func isJSONFeed(data []byte) bool {
+	var probe struct {
+		Version string          `json:"version"`
+		Items   json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return strings.Contains(probe.Version, "jsonfeed.org") || len(probe.Items) > 0
+}
+
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/json":      true,
+}
+
+// discoverFeed looks for <link rel="alternate"> elements announcing a feed
+// in an HTML page and returns the first one resolved against base.
+func discoverFeed(page []byte, base *url.URL) string {
+	decoder := xml.NewDecoder(bytes.NewReader(page))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		elem, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(elem.Name.Local) {
+		case "link":
+		case "body":
+			// Feed links live in <head>.
+			return ""
+		default:
+			continue
+		}
+
+		var rel, linkType, href string
+		for _, attr := range elem.Attr {
+			switch strings.ToLower(attr.Name.Local) {
+			case "rel":
+				rel = strings.ToLower(attr.Value)
+			case "type":
+				linkType = strings.ToLower(strings.TrimSpace(attr.Value))
+			case "href":
+				href = strings.TrimSpace(attr.Value)
+			}
+		}
+		if href == "" || !feedLinkTypes[linkType] || !hasToken(rel, "alternate") {
+			continue
+		}
+		ref, err := url.Parse(href)
+		if err != nil {
+			continue
+		}
+		if base != nil {
+			ref = base.ResolveReference(ref)
+		}
+		return ref.String()
+	}
+}
+
+func hasToken(list, token string) bool {
+	for _, field := range strings.Fields(list) {
+		if field == token {
+			return true
+		}
+	}
+	return false
+}
+
+// feedLocation returns the URL to request for a configured feed: the feed
+// discovered from its page on an earlier fetch, or the URL itself.
+func feedLocation(feedURL string) string {
+	discoveredMu.Lock()
+	defer discoveredMu.Unlock()
+	if discovered, ok := discoveredURLs[feedURL]; ok {
+		return discovered
+	}
+	return feedURL
+}
+
+func rememberDiscovered(feedURL, discovered string) {
+	discoveredMu.Lock()
+	discoveredURLs[feedURL] = discovered
+	discoveredMu.Unlock()
+}
+
+func forgetDiscovered(feedURL string) {
+	discoveredMu.Lock()
+	delete(discoveredURLs, feedURL)
+	discoveredMu.Unlock()
+}
@@ -4,69 +4,147 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"news-aggregator/config"
 	"news-aggregator/models"
-	"strings"
+	"news-aggregator/utils"
 	"time"
 )
 
-type ErrorURL struct {
-	URL   string
-	Error string
-	Time  time.Time
-}
-
-var ErrorURLs []ErrorURL
+const (
+	DefaultTimeout   = 10 * time.Second
+	DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+)
 
-func FetchNews(feedURL string, category string) []models.NewsItem {
+func FetchNews(feed config.FeedConfig) []models.NewsItem {
+	feedURL, category := feed.URL, feed.Category
 	if feedURL == "" {
 		log.Println("Empty feed URL")
 	}
+	timeout := feed.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	userAgent := feed.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
 	client := &http.Client{
-		Timeout: time.Second * 10,
+		Timeout: timeout,
 	}
-	req, _ := http.NewRequest("GET", feedURL, nil)
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 
+	start := time.Now()
+	requestURL := feedLocation(feedURL)
+	for {
+		req, _ := http.NewRequest("GET", requestURL, nil)
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Accept", "application/rss+xml,application/atom+xml,application/feed+json,text/html,application/xhtml+xml,application/xml;q=0.9,application/json;q=0.9,*/*;q=0.8")
+		setConditionalHeaders(req, feedURL)
+
+		resp, body, err := download(client, req)
+		if err != nil {
+			fail(feedURL, ErrorNetwork, err.Error(), time.Since(start))
+			return nil
+		}
+
+		if resp.StatusCode == http.StatusNotModified {
+			if items, ok := cachedItems(feedURL); ok {
+				log.Println("Feed not modified:", feedURL)
+				touchSeen(items)
+				if feed.FullText {
+					items = append([]models.NewsItem(nil), items...)
+					queueFullText(items, userAgent)
+					FillFullText(items)
+				}
+				recordSuccess(feedURL, len(items), time.Since(start))
+				return items
+			}
+		}
+		if resp.StatusCode != http.StatusOK && requestURL != feedURL {
+			// The discovered feed moved away; look for it again next time.
+			forgetDiscovered(feedURL)
+		}
+		if resp.StatusCode == http.StatusGone {
+			log.Println("Feed is gone, disabling it:", feedURL)
+			recordFailure(feedURL, ErrorHTTP, resp.Status, time.Since(start), true)
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			fail(feedURL, ErrorHTTP, resp.Status, time.Since(start))
+			return nil
+		}
+
+		body = decodeBody(resp.Header.Get("Content-Type"), body)
+		//log.Println("Feed content:", string(body)[:400])
+		var items []models.NewsItem
+		switch detectFormat(resp.Header.Get("Content-Type"), body) {
+		case formatRSS:
+			log.Println("RSS feed detected:", feedURL)
+			var rss *RSS
+			rss, items, err = parseRSS(body, category)
+			if rss != nil {
+				rememberSchedule(feedURL, rss)
+			}
+		case formatAtom:
+			log.Println("Atom feed detected", feedURL)
+			items, err = parseAtom(body, category)
+		case formatJSON:
+			log.Println("JSON feed detected", feedURL)
+			items, err = parseJSONFeed(body, category)
+		case formatHTML:
+			discovered := ""
+			if requestURL == feedURL {
+				discovered = discoverFeed(body, resp.Request.URL)
+			}
+			if discovered == "" {
+				fail(feedURL, ErrorFormat, "HTML page without a feed link", time.Since(start))
+				return nil
+			}
+			log.Printf("Discovered feed %s on page %s", discovered, feedURL)
+			rememberDiscovered(feedURL, discovered)
+			requestURL = discovered
+			continue
+		default:
+			fail(feedURL, ErrorFormat, "unknown feed format", time.Since(start))
+			return nil
+		}
+		if err != nil {
+			fail(feedURL, ErrorParse, err.Error(), time.Since(start))
+			return nil
+		}
+		for i := range items {
+			items[i].FeedURL = feedURL
+			items[i].ID = canonicalID(feedURL, items[i])
+			if feed.Title != "" {
+				items[i].ChannelTitle = feed.Title
+			}
+		}
+		items = utils.DedupeByID(items)
+		if feed.FullText {
+			queueFullText(items, userAgent)
+			FillFullText(items)
+		}
+		rememberResponse(feedURL, resp, items)
+		recordSuccess(feedURL, len(items), time.Since(start))
+		return items
+	}
+}
+
+// download performs req and reads the whole body.
+func download(client *http.Client, req *http.Request) (*http.Response, []byte, error) {
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Println("Error fetching feed:", err)
-		return nil
-	}
-	if resp.StatusCode != http.StatusOK {
-		ErrorURLs = append(ErrorURLs, ErrorURL{URL: feedURL, Error: resp.Status, Time: time.Now()})
-		return nil
+		return nil, nil, err
 	}
-
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("Error reading feed:", err)
-		return nil
-	}
-	CheckError()
-	content := string(body)
-	//log.Println("Feed content:", content[:400])
-	if strings.Contains(content, "<rss") {
-		log.Println("RSS feed detected:", feedURL)
-		return ParseRSS(body, category)
-	} else if strings.Contains(content, "<feed") {
-		log.Println("Atom feed detected", feedURL)
-		return ParseAtom(body, category)
-	} else {
-		log.Println("Unknown feed format", feedURL)
-		return nil
+		return nil, nil, err
 	}
+	return resp, body, nil
 }
 
-func CheckError() {
-	if len(ErrorURLs) > 0 {
-		log.Println("Error URLs:")
-		for _, errURL := range ErrorURLs {
-			log.Printf("Time: %s, URL: %s, Error: %s\n", errURL.Time.Format(time.RFC3339), errURL.URL, errURL.Error)
-		}
-	} else {
-		log.Println("No errors in URLs")
-	}
+func fail(feedURL string, kind ErrorKind, message string, responseTime time.Duration) {
+	log.Printf("Error fetching feed %s (%s): %s", feedURL, kind, message)
+	recordFailure(feedURL, kind, message, responseTime, false)
 }
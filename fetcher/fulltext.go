@@ -0,0 +1,192 @@
+package fetcher
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"news-aggregator/models"
+	"news-aggregator/utils"
+	"sync"
+	"time"
+)
+
+// FullTextRetry is how long a page without an extractable article is left
+// alone before it is tried again. FullTextRetention is how long articles are
+// kept after their item was last in its feed.
+var (
+	FullTextRetry     = 6 * time.Hour
+	FullTextRetention = 30 * 24 * time.Hour
+)
+
+// Article pages are downloaded after the feeds, not by the feed workers:
+// at most FullTextPerCycle pages per update, FullTextWorkers at a time, each
+// given FullTextTimeout.
+var (
+	FullTextPerCycle = 20
+	FullTextWorkers  = 2
+	FullTextTimeout  = 5 * time.Second
+)
+
+type fullTextEntry struct {
+	Content   template.HTML `json:"content"`
+	FetchedAt time.Time     `json:"fetchedAt"`
+	LastSeen  time.Time     `json:"lastSeen"`
+}
+
+type pendingArticle struct {
+	id        string
+	link      string
+	userAgent string
+}
+
+var (
+	fullTextMu    sync.Mutex
+	fullTexts     = make(map[string]fullTextEntry)
+	pending       []pendingArticle
+	pendingQueued = make(map[string]bool)
+)
+
+// FillFullText replaces the content of items with the articles extracted
+// from their pages so far.
+func FillFullText(items []models.NewsItem) {
+	fullTextMu.Lock()
+	defer fullTextMu.Unlock()
+
+	now := time.Now()
+	for i := range items {
+		entry, ok := fullTexts[items[i].ID]
+		if !ok {
+			continue
+		}
+		entry.LastSeen = now
+		fullTexts[items[i].ID] = entry
+		if entry.Content != "" {
+			items[i].Content = entry.Content
+		}
+	}
+}
+
+// queueFullText schedules the pages of items that have no article yet for
+// ExtractFullText. Each page is downloaded only once.
+func queueFullText(items []models.NewsItem, userAgent string) {
+	fullTextMu.Lock()
+	defer fullTextMu.Unlock()
+
+	for _, item := range items {
+		if item.ItemLink == "" || pendingQueued[item.ID] {
+			continue
+		}
+		entry, ok := fullTexts[item.ID]
+		if ok && (entry.Content != "" || time.Since(entry.FetchedAt) <= FullTextRetry) {
+			continue
+		}
+		pending = append(pending, pendingArticle{id: item.ID, link: item.ItemLink, userAgent: userAgent})
+		pendingQueued[item.ID] = true
+	}
+}
+
+// ExtractFullText downloads up to FullTextPerCycle queued pages and
+// returns how many articles it found. The rest wait for the next call.
+func ExtractFullText() int {
+	fullTextMu.Lock()
+	batch := append([]pendingArticle(nil), pending[:min(len(pending), FullTextPerCycle)]...)
+	pending = pending[len(batch):]
+	for _, p := range batch {
+		delete(pendingQueued, p.id)
+	}
+	fullTextMu.Unlock()
+
+	client := &http.Client{Timeout: FullTextTimeout}
+	sem := make(chan struct{}, FullTextWorkers)
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		found int
+	)
+	for _, p := range batch {
+		wg.Add(1)
+		go func(p pendingArticle) {
+			defer wg.Done()
+			sem <- struct{}{}
+			content := fetchFullText(client, p.userAgent, p.link)
+			<-sem
+
+			storeFullText(p.id, content)
+			if content != "" {
+				mu.Lock()
+				found++
+				mu.Unlock()
+			}
+		}(p)
+	}
+	wg.Wait()
+	return found
+}
+
+func storeFullText(id string, content template.HTML) {
+	fullTextMu.Lock()
+	defer fullTextMu.Unlock()
+
+	now := time.Now()
+	fullTexts[id] = fullTextEntry{Content: content, FetchedAt: now, LastSeen: now}
+}
+
+func fetchFullText(client *http.Client, userAgent, link string) template.HTML {
+	req, err := http.NewRequest("GET", link, nil)
+	if err != nil {
+		log.Printf("Error fetching article %s: %v", link, err)
+		return ""
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+
+	resp, body, err := download(client, req)
+	if err != nil {
+		log.Printf("Error fetching article %s: %v", link, err)
+		return ""
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Error fetching article %s: %s", link, resp.Status)
+		return ""
+	}
+
+	body = decodeBody(resp.Header.Get("Content-Type"), body)
+	content := utils.ExtractArticle(string(body), resp.Request.URL.String())
+	if content == "" {
+		log.Println("No article found at", link)
+	}
+	return content
+}
+
+// LoadFullText restores articles saved by SaveFullText.
+func LoadFullText(filename string) error {
+	entries := make(map[string]fullTextEntry)
+	if err := loadJSON(filename, &entries); err != nil {
+		return err
+	}
+
+	fullTextMu.Lock()
+	defer fullTextMu.Unlock()
+	for id, entry := range entries {
+		fullTexts[id] = entry
+	}
+	return nil
+}
+
+// SaveFullText writes the extracted articles, dropping those whose item was
+// not seen for FullTextRetention.
+func SaveFullText(filename string) error {
+	fullTextMu.Lock()
+	cutoff := time.Now().Add(-FullTextRetention)
+	snapshot := make(map[string]fullTextEntry, len(fullTexts))
+	for id, entry := range fullTexts {
+		if entry.LastSeen.Before(cutoff) {
+			delete(fullTexts, id)
+			continue
+		}
+		snapshot[id] = entry
+	}
+	fullTextMu.Unlock()
+
+	return saveJSON(filename, snapshot)
+}
@@ -0,0 +1,187 @@
+package fetcher
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+type ErrorKind string
+
+const (
+	ErrorNetwork ErrorKind = "network"
+	ErrorHTTP    ErrorKind = "http"
+	ErrorParse   ErrorKind = "parse"
+	ErrorFormat  ErrorKind = "format"
+)
+
+// MaxBackoff caps the delay between retries of a failing feed. DeadAfter is
+// how long a feed may keep failing before it is marked dead.
+var (
+	MaxBackoff = 24 * time.Hour
+	DeadAfter  = 7 * 24 * time.Hour
+)
+
+type FeedHealth struct {
+	URL                 string        `json:"url"`
+	LastAttempt         time.Time     `json:"lastAttempt"`
+	LastSuccess         time.Time     `json:"lastSuccess"`
+	LastError           string        `json:"lastError,omitempty"`
+	LastErrorKind       ErrorKind     `json:"lastErrorKind,omitempty"`
+	LastErrorTime       time.Time     `json:"lastErrorTime"`
+	FirstFailure        time.Time     `json:"firstFailure"`
+	ConsecutiveFailures int           `json:"consecutiveFailures"`
+	Dead                bool          `json:"dead"`
+	ItemCount           int           `json:"itemCount"`
+	ResponseTime        time.Duration `json:"responseTime"`
+}
+
+var (
+	feedHealth = make(map[string]*FeedHealth)
+	healthMu   sync.RWMutex
+)
+
+func healthEntry(feedURL string) *FeedHealth {
+	entry, ok := feedHealth[feedURL]
+	if !ok {
+		entry = &FeedHealth{URL: feedURL}
+		feedHealth[feedURL] = entry
+	}
+	return entry
+}
+
+// recordSuccess marks the feed as healthy and clears any previous error.
+func recordSuccess(feedURL string, itemCount int, responseTime time.Duration) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+
+	entry := healthEntry(feedURL)
+	entry.LastAttempt = time.Now()
+	entry.LastSuccess = entry.LastAttempt
+	entry.LastError = ""
+	entry.LastErrorKind = ""
+	entry.LastErrorTime = time.Time{}
+	entry.FirstFailure = time.Time{}
+	entry.ConsecutiveFailures = 0
+	entry.ItemCount = itemCount
+	entry.ResponseTime = responseTime
+}
+
+// recordFailure counts a failed fetch. The feed is marked dead when it has
+// been failing for DeadAfter, or right away when the failure is permanent
+// (HTTP 410 Gone).
+func recordFailure(feedURL string, kind ErrorKind, message string, responseTime time.Duration, permanent bool) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+
+	entry := healthEntry(feedURL)
+	entry.LastAttempt = time.Now()
+	entry.LastError = message
+	entry.LastErrorKind = kind
+	entry.LastErrorTime = entry.LastAttempt
+	if entry.ConsecutiveFailures == 0 {
+		entry.FirstFailure = entry.LastAttempt
+	}
+	entry.ConsecutiveFailures++
+	entry.ResponseTime = responseTime
+	if permanent || entry.LastAttempt.Sub(entry.FirstFailure) >= DeadAfter {
+		entry.Dead = true
+	}
+}
+
+// backoff returns how long to wait before retrying a failing feed: interval
+// doubled for every failure after the first, capped at MaxBackoff and
+// spread by ±20% so that feeds failing together don't retry together.
+func backoff(feedURL string, interval time.Duration) time.Duration {
+	healthMu.RLock()
+	failures := 0
+	if entry, ok := feedHealth[feedURL]; ok {
+		failures = entry.ConsecutiveFailures
+	}
+	healthMu.RUnlock()
+
+	if failures <= 1 {
+		return interval
+	}
+	delay := interval
+	for i := 1; i < failures && delay < MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > MaxBackoff {
+		delay = MaxBackoff
+	}
+	jitter := 0.8 + 0.4*rand.Float64()
+	return time.Duration(float64(delay) * jitter)
+}
+
+// IsDead reports whether the feed was given up on.
+func IsDead(feedURL string) bool {
+	healthMu.RLock()
+	defer healthMu.RUnlock()
+
+	entry, ok := feedHealth[feedURL]
+	return ok && entry.Dead
+}
+
+// Revive clears the dead flag and failure history of a feed so that it is
+// fetched again on the next scheduler tick.
+func Revive(feedURL string) {
+	healthMu.Lock()
+	if entry, ok := feedHealth[feedURL]; ok {
+		entry.Dead = false
+		entry.FirstFailure = time.Time{}
+		entry.ConsecutiveFailures = 0
+	}
+	healthMu.Unlock()
+
+	hintsMu.Lock()
+	delete(nextFetch, feedURL)
+	hintsMu.Unlock()
+}
+
+// Health returns a copy of the health entry for feedURL.
+func Health(feedURL string) (FeedHealth, bool) {
+	healthMu.RLock()
+	defer healthMu.RUnlock()
+
+	entry, ok := feedHealth[feedURL]
+	if !ok {
+		return FeedHealth{URL: feedURL}, false
+	}
+	return *entry, true
+}
+
+// HealthReport returns copies of all health entries sorted by URL.
+func HealthReport() []FeedHealth {
+	healthMu.RLock()
+	defer healthMu.RUnlock()
+
+	report := make([]FeedHealth, 0, len(feedHealth))
+	for _, entry := range feedHealth {
+		report = append(report, *entry)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].URL < report[j].URL
+	})
+	return report
+}
+
+// LoadHealth restores the registry saved by SaveHealth.
+func LoadHealth(filename string) error {
+	var entries []FeedHealth
+	if err := loadJSON(filename, &entries); err != nil {
+		return err
+	}
+
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	for i := range entries {
+		feedHealth[entries[i].URL] = &entries[i]
+	}
+	return nil
+}
+
+func SaveHealth(filename string) error {
+	return saveJSON(filename, HealthReport())
+}
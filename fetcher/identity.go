@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/url"
+	"news-aggregator/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// trackingParams are query parameters that don't change which page a link
+// points to.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "yclid": true, "mc_cid": true, "mc_eid": true,
+	"_hsenc": true, "_hsmi": true, "igshid": true, "ref": true, "ref_src": true,
+	"cmpid": true, "ito": true, "rss": true,
+}
+
+// canonicalID identifies an item across refreshes and feeds. It is taken
+// from the GUID (or Atom id), then the normalized link, then the title and
+// date. GUIDs that aren't URLs or URNs are only unique within their feed,
+// so they are scoped to it.
+func canonicalID(feedURL string, item models.NewsItem) string {
+	var key string
+	switch guid := strings.TrimSpace(item.Guid); {
+	case isGlobalID(guid):
+		if link, ok := normalizeLink(guid); ok {
+			key = "link:" + link
+		} else {
+			key = "id:" + guid
+		}
+	case guid != "":
+		key = "id:" + feedURL + "\n" + guid
+	default:
+		if link, ok := normalizeLink(item.ItemLink); ok {
+			key = "link:" + link
+		} else {
+			key = "hash:" + feedURL + "\n" + strings.TrimSpace(item.Title) + "\n" + item.PubDate.UTC().Format(time.RFC3339)
+		}
+	}
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+func isGlobalID(guid string) bool {
+	lower := strings.ToLower(guid)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") ||
+		strings.HasPrefix(lower, "urn:") || strings.HasPrefix(lower, "tag:")
+}
+
+// normalizeLink reduces an article URL to a form that is the same for
+// every way publishers and aggregators write it: scheme, "www.", fragment,
+// tracking parameters and trailing slashes are dropped and the remaining
+// query is sorted.
+func normalizeLink(link string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+	default:
+		return "", false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	query := u.Query()
+	for name := range query {
+		if trackingParams[strings.ToLower(name)] || strings.HasPrefix(strings.ToLower(name), "utm_") {
+			query.Del(name)
+		}
+	}
+	keys := make([]string, 0, len(query))
+	for name := range query {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, name := range keys {
+		for _, value := range query[name] {
+			params = append(params, url.QueryEscape(name)+"="+url.QueryEscape(value))
+		}
+	}
+
+	normalized := host + strings.TrimRight(u.EscapedPath(), "/")
+	if len(params) > 0 {
+		normalized += "?" + strings.Join(params, "&")
+	}
+	return normalized, true
+}
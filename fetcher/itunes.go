@@ -0,0 +1,55 @@
+package fetcher
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ITunes holds the itunes: elements podcast feeds add to their items.
+type ITunes struct {
+	Duration string       `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration,omitempty"`
+	Episode  string       `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode,omitempty"`
+	Season   string       `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd season,omitempty"`
+	Image    *ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image,omitempty"`
+	Explicit string       `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd explicit,omitempty"`
+	Summary  string       `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd summary,omitempty"`
+}
+
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// parseITunesDuration accepts "HH:MM:SS", "MM:SS" and plain seconds.
+func parseITunesDuration(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	var seconds float64
+	for _, part := range strings.Split(value, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// parseITunesExplicit accepts the "yes"/"explicit"/"true" spellings used
+// across versions of the spec.
+func parseITunesExplicit(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "explicit", "true":
+		return true
+	}
+	return false
+}
+
+func itunesImageURL(image *ITunesImage) string {
+	if image == nil {
+		return ""
+	}
+	return strings.TrimSpace(image.Href)
+}
@@ -0,0 +1,177 @@
+package fetcher
+
+import (
+	"encoding/json"
+	"html"
+	"html/template"
+	"news-aggregator/models"
+	"news-aggregator/utils"
+	"strings"
+)
+
+type JSONFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url,omitempty"`
+	FeedURL     string           `json:"feed_url,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Icon        string           `json:"icon,omitempty"`
+	Favicon     string           `json:"favicon,omitempty"`
+	Author      *JSONFeedAuthor  `json:"author,omitempty"`
+	Authors     []JSONFeedAuthor `json:"authors,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Expired     bool             `json:"expired,omitempty"`
+	Items       []JSONFeedItem   `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            JSONFeedID           `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	ExternalURL   string               `json:"external_url,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	ContentHTML   string               `json:"content_html,omitempty"`
+	ContentText   string               `json:"content_text,omitempty"`
+	Summary       string               `json:"summary,omitempty"`
+	Image         string               `json:"image,omitempty"`
+	BannerImage   string               `json:"banner_image,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	DateModified  string               `json:"date_modified,omitempty"`
+	Author        *JSONFeedAuthor      `json:"author,omitempty"`
+	Authors       []JSONFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Attachments   []JSONFeedAttachment `json:"attachments,omitempty"`
+}
+
+type JSONFeedAuthor struct {
+	Name   string `json:"name,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Avatar string `json:"avatar,omitempty"`
+}
+
+type JSONFeedAttachment struct {
+	URL               string  `json:"url"`
+	MimeType          string  `json:"mime_type"`
+	Title             string  `json:"title,omitempty"`
+	SizeInBytes       int64   `json:"size_in_bytes,omitempty"`
+	DurationInSeconds float64 `json:"duration_in_seconds,omitempty"`
+}
+
+// JSONFeedID is a string, but some publishers emit numeric ids.
+type JSONFeedID string
+
+func (id *JSONFeedID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = JSONFeedID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = JSONFeedID(n.String())
+	return nil
+}
+
+func ParseJSONFeed(data []byte, category string) []models.NewsItem {
+	items, _ := parseJSONFeed(data, category)
+	return items
+}
+
+func parseJSONFeed(data []byte, category string) ([]models.NewsItem, error) {
+	var feed JSONFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return nil, err
+	}
+	if feed.Title == "" {
+		feed.Title = "Title is missing"
+	}
+
+	favicon := feed.Favicon
+	if favicon == "" {
+		favicon = utils.GetFaviconURL(feed.HomePageURL)
+	}
+	feedAuthors := jsonFeedAuthorNames(feed.Author, feed.Authors)
+
+	var newsItems []models.NewsItem
+	for _, item := range feed.Items {
+		var cleanedDescription string
+		var content template.HTML
+		if item.ContentHTML != "" {
+			cleanedDescription = utils.PlainText(item.ContentHTML)
+			content = utils.SanitizeHTML(item.ContentHTML, item.URL)
+		} else if item.ContentText != "" {
+			cleanedDescription = item.ContentText
+			content = template.HTML(html.EscapeString(item.ContentText))
+		} else {
+			cleanedDescription = item.Summary
+			content = template.HTML(html.EscapeString(item.Summary))
+		}
+
+		// Microblog style feeds often leave the title out.
+		title := item.Title
+		if title == "" {
+			title = utils.TruncateDescription(cleanedDescription, 80)
+		}
+
+		itemLink := item.URL
+		if itemLink == "" {
+			itemLink = item.ExternalURL
+		}
+
+		pubTime, approximate := itemDate(itemKey(string(item.ID), itemLink, title), title,
+			[]string{item.DatePublished, item.DateModified}, nil)
+
+		var enclosures []mediaFile
+		for _, a := range item.Attachments {
+			enclosures = append(enclosures, mediaFile{URL: a.URL, Type: a.MimeType})
+		}
+		var media Media
+		if item.Image != "" {
+			media.Thumbnails = []MediaThumbnail{{URL: item.Image}}
+		} else if item.BannerImage != "" {
+			media.Thumbnails = []MediaThumbnail{{URL: item.BannerImage}}
+		}
+		mediaURL, mediaType, thumbnail := itemMedia(enclosures, media, item.ContentHTML, itemLink)
+
+		creator := jsonFeedAuthorNames(item.Author, item.Authors)
+		if creator == "" {
+			creator = feedAuthors
+		}
+
+		newsItems = append(newsItems, models.NewsItem{
+			Title:           title,
+			Description:     cleanedDescription,
+			ChannelLink:     feed.HomePageURL,
+			PubDate:         pubTime,
+			DateApproximate: approximate,
+			Content:         content,
+			MediaURL:        mediaURL,
+			MediaType:       mediaType,
+			Thumbnail:       thumbnail,
+			Creator:         creator,
+			Guid:            string(item.ID),
+			ItemLink:        itemLink,
+			ChannelTitle:    feed.Title,
+			Category:        category,
+			Favicon:         favicon,
+		})
+	}
+
+	return newsItems, nil
+}
+
+// jsonFeedAuthorNames joins the 1.1 authors list, falling back to the 1.0
+// single author.
+func jsonFeedAuthorNames(author *JSONFeedAuthor, authors []JSONFeedAuthor) string {
+	var names []string
+	for _, a := range authors {
+		if a.Name != "" {
+			names = append(names, a.Name)
+		}
+	}
+	if len(names) == 0 && author != nil && author.Name != "" {
+		names = append(names, author.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,138 @@
+package fetcher
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Media holds the Media RSS (media:) elements of an RSS item or Atom entry.
+type Media struct {
+	Contents   []MediaContent   `xml:"http://search.yahoo.com/mrss/ content,omitempty"`
+	Thumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail,omitempty"`
+	Groups     []MediaGroup     `xml:"http://search.yahoo.com/mrss/ group,omitempty"`
+}
+
+type MediaContent struct {
+	URL        string           `xml:"url,attr"`
+	Type       string           `xml:"type,attr,omitempty"`
+	Medium     string           `xml:"medium,attr,omitempty"`
+	IsDefault  bool             `xml:"isDefault,attr,omitempty"`
+	Width      int              `xml:"width,attr,omitempty"`
+	Height     int              `xml:"height,attr,omitempty"`
+	Thumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail,omitempty"`
+}
+
+type MediaThumbnail struct {
+	URL    string `xml:"url,attr"`
+	Width  int    `xml:"width,attr,omitempty"`
+	Height int    `xml:"height,attr,omitempty"`
+}
+
+type MediaGroup struct {
+	Contents   []MediaContent   `xml:"http://search.yahoo.com/mrss/ content,omitempty"`
+	Thumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail,omitempty"`
+}
+
+// mediaFile is a candidate for NewsItem.MediaURL.
+type mediaFile struct {
+	URL    string
+	Type   string
+	Medium string
+}
+
+var imgSrcRe = regexp.MustCompile(`(?is)<img\s[^>]*?\bsrc\s*=\s*["']?([^"'\s>]+)`)
+
+// itemMedia picks the item's media file and the image shown on its card.
+// Enclosures come first, then media:content (direct or grouped); the first
+// <img> in the item's HTML is used when the feed declares nothing.
+func itemMedia(enclosures []mediaFile, media Media, html, base string) (mediaURL, mediaType, thumbnail string) {
+	files := enclosures
+	thumbs := media.Thumbnails
+	addContents := func(contents []MediaContent) {
+		for _, c := range contents {
+			f := mediaFile{URL: c.URL, Type: c.Type, Medium: c.Medium}
+			if c.IsDefault {
+				files = append([]mediaFile{f}, files...)
+			} else {
+				files = append(files, f)
+			}
+			thumbs = append(thumbs, c.Thumbnails...)
+		}
+	}
+	addContents(media.Contents)
+	for _, g := range media.Groups {
+		addContents(g.Contents)
+		thumbs = append(thumbs, g.Thumbnails...)
+	}
+
+	for _, f := range files {
+		if f.URL == "" {
+			continue
+		}
+		if mediaURL == "" {
+			mediaURL = resolveURL(base, f.URL)
+			mediaType = mediaFileType(f)
+		}
+		if thumbnail == "" && strings.HasPrefix(mediaFileType(f), "image/") {
+			thumbnail = resolveURL(base, f.URL)
+		}
+	}
+	for _, t := range thumbs {
+		if t.URL != "" {
+			thumbnail = resolveURL(base, t.URL)
+			break
+		}
+	}
+
+	if mediaURL == "" || thumbnail == "" {
+		if img := imgSrcRe.FindStringSubmatch(html); img != nil {
+			src := resolveURL(base, img[1])
+			if mediaURL == "" {
+				mediaURL = src
+				mediaType = mediaFileType(mediaFile{URL: src, Medium: "image"})
+			}
+			if thumbnail == "" {
+				thumbnail = src
+			}
+		}
+	}
+	if mediaURL == "" && thumbnail != "" {
+		mediaURL = thumbnail
+		mediaType = mediaFileType(mediaFile{URL: thumbnail, Medium: "image"})
+	}
+	return mediaURL, mediaType, thumbnail
+}
+
+// mediaFileType returns the declared MIME type, or guesses it from the file
+// extension and the Media RSS medium.
+func mediaFileType(f mediaFile) string {
+	if f.Type != "" {
+		return f.Type
+	}
+	if u, err := url.Parse(f.URL); err == nil {
+		if t := mime.TypeByExtension(path.Ext(u.Path)); t != "" {
+			return t
+		}
+	}
+	switch f.Medium {
+	case "image", "audio", "video":
+		return f.Medium + "/*"
+	}
+	return ""
+}
+
+func resolveURL(base, ref string) string {
+	ref = strings.TrimSpace(ref)
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
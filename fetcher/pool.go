@@ -0,0 +1,74 @@
+package fetcher
+
+import (
+	"net/url"
+	"news-aggregator/config"
+	"news-aggregator/models"
+	"sync"
+)
+
+const (
+	DefaultWorkers = 8
+	DefaultPerHost = 2
+)
+
+// FetchAll fetches feeds in parallel. At most workers feeds are fetched at
+// once and at most perHost of them share the same host. progress is called
+// after every finished feed. Results are returned in the order of feeds.
+func FetchAll(feeds []config.FeedConfig, workers, perHost int, progress func(feed config.FeedConfig, done, total int)) [][]models.NewsItem {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if perHost <= 0 {
+		perHost = DefaultPerHost
+	}
+
+	sem := make(chan struct{}, workers)
+	hostSems := make(map[string]chan struct{})
+	for _, feed := range feeds {
+		host := feedHost(feed.URL)
+		if _, ok := hostSems[host]; !ok {
+			hostSems[host] = make(chan struct{}, perHost)
+		}
+	}
+
+	results := make([][]models.NewsItem, len(feeds))
+	var (
+		wg       sync.WaitGroup
+		progMu   sync.Mutex
+		finished int
+	)
+	for i, feed := range feeds {
+		wg.Add(1)
+		go func(i int, feed config.FeedConfig) {
+			defer wg.Done()
+
+			// Wait for the host slot first so that feeds queued behind a busy
+			// host don't hold a worker slot.
+			hostSem := hostSems[feedHost(feed.URL)]
+			hostSem <- struct{}{}
+			sem <- struct{}{}
+			results[i] = FetchNews(feed)
+			<-sem
+			<-hostSem
+
+			if progress != nil {
+				progMu.Lock()
+				finished++
+				progress(feed, finished, len(feeds))
+				progMu.Unlock()
+			}
+		}(i, feed)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func feedHost(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return feedURL
+	}
+	return u.Host
+}
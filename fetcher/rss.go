@@ -2,13 +2,12 @@ package fetcher
 
 import (
 	"encoding/xml"
-	"html/template"
 	"io"
 	"log"
 	"news-aggregator/models"
 	"news-aggregator/utils"
+	"strconv"
 	"strings"
-	"time"
 )
 
 type RSS struct {
@@ -27,6 +26,7 @@ type RSS struct {
 	Docs           string            `xml:"channel>docs,omitempty"`
 	Cloud          *Cloud            `xml:"channel>cloud,omitempty"`
 	TTL            int               `xml:"channel>ttl,omitempty"`
+	ITunesImage    *ITunesImage      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd channel>image,omitempty"`
 	Image          *Image            `xml:"channel>image,omitempty"`
 	Rating         string            `xml:"channel>rating,omitempty"`
 	TextInput      *TextInput        `xml:"channel>textInput,omitempty"`
@@ -69,10 +69,17 @@ type SkipDays struct {
 	Days []string `xml:"day"`
 }
 
+// Item.Title and Item.Description are filled by parseRSS from Titles and
+// Descriptions: a field without a namespace matches an element of any
+// namespace, so those also catch dc:title, itunes:title, media:title and the
+// like.
 type Item struct {
-	Title       string `xml:"title,omitempty"`
-	Link        string `xml:"link,omitempty"`
-	Description string `xml:"description,omitempty"`
+	XMLName      xml.Name
+	Title        string        `xml:"-"`
+	Link         string        `xml:"link,omitempty"`
+	Description  string        `xml:"-"`
+	Titles       []ItemElement `xml:"title"`
+	Descriptions []ItemElement `xml:"description"`
 
 	Author    string     `xml:"author,omitempty"`
 	Category  []string   `xml:"category,omitempty"`
@@ -82,9 +89,27 @@ type Item struct {
 	PubDate   string     `xml:"pubDate,omitempty"`
 	Source    *Source    `xml:"source,omitempty"`
 
-	About   string      `xml:"about,attr,omitempty"`
-	DC      *DublinCore `xml:"http://purl.org/dc/elements/1.1/ dc,omitempty"`
-	Content *Content    `xml:"http://purl.org/rss/1.0/modules/content/ encoded,omitempty"`
+	About string `xml:"about,attr,omitempty"`
+	DublinCore
+	Media
+	ITunes
+	Content *Content `xml:"http://purl.org/rss/1.0/modules/content/ encoded,omitempty"`
+}
+
+type ItemElement struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// itemText returns the text of the element of elements in namespace space,
+// or fallback when there is none.
+func itemText(elements []ItemElement, space, fallback string) string {
+	for _, element := range elements {
+		if element.XMLName.Space == space {
+			return element.Value
+		}
+	}
+	return fallback
 }
 
 type Enclosure struct {
@@ -103,14 +128,17 @@ type Source struct {
 	URL   string `xml:"url,attr"`
 }
 
+const dcSpace = "http://purl.org/dc/elements/1.1/"
+
+// DublinCore holds the dc: elements used by RSS 1.0 items and by many RSS
+// 2.0 feeds. dc:title and dc:description are read into Item.Titles and
+// Item.Descriptions.
 type DublinCore struct {
-	Creator     string    `xml:"creator,omitempty"`
-	Date        time.Time `xml:"date,omitempty"`
-	Description string    `xml:"description,omitempty"`
-	Language    string    `xml:"language,omitempty"`
-	Publisher   string    `xml:"publisher,omitempty"`
-	Subject     string    `xml:"subject,omitempty"`
-	Title       string    `xml:"title,omitempty"`
+	Creator   string `xml:"http://purl.org/dc/elements/1.1/ creator,omitempty"`
+	Date      string `xml:"http://purl.org/dc/elements/1.1/ date,omitempty"`
+	Language  string `xml:"http://purl.org/dc/elements/1.1/ language,omitempty"`
+	Publisher string `xml:"http://purl.org/dc/elements/1.1/ publisher,omitempty"`
+	Subject   string `xml:"http://purl.org/dc/elements/1.1/ subject,omitempty"`
 }
 
 type Content struct {
@@ -118,9 +146,14 @@ type Content struct {
 }
 
 func ParseRSS(data []byte, category string) []models.NewsItem {
+	_, items, _ := parseRSS(data, category)
+	return items
+}
+
+func parseRSS(data []byte, category string) (*RSS, []models.NewsItem, error) {
 	var rss RSS
-	if err := xml.Unmarshal(data, &rss); err != nil {
-		return nil
+	if err := newXMLDecoder(data).Decode(&rss); err != nil {
+		return nil, nil, err
 	}
 	// log.Printf("Channel Title: %s", rss.Title)
 	// log.Printf("Channel Link: %s", rss.Link)
@@ -133,36 +166,92 @@ func ParseRSS(data []byte, category string) []models.NewsItem {
 		log.Println("Main link field empty or not find, trying alternative parsing -", rss.Title)
 	}
 
+	// RSS 1.0 (RDF) keeps its items next to the channel instead of inside it.
+	items := rss.Items
+	if len(items) == 0 {
+		items = rss.RDFItems
+	}
+
 	var newsItems []models.NewsItem
-	for _, item := range rss.Items {
-		cleanedDescription := utils.StripHTMLTags(string(item.Description))
-		var pubTime time.Time
-		pubTime, err := utils.FormatDate(item.PubDate)
-		if err != nil {
-			log.Printf("Failed to parse date '%s' for item '%s': %v",
-				item.PubDate, item.Title, err)
-			continue
+	for _, item := range items {
+		item.Title = itemText(item.Titles, item.XMLName.Space, itemText(item.Titles, dcSpace, ""))
+		item.Description = itemText(item.Descriptions, item.XMLName.Space, itemText(item.Descriptions, dcSpace, ""))
+		cleanedDescription := utils.PlainText(item.Description)
+		summary := utils.PlainText(item.ITunes.Summary)
+		var encoded string
+		if item.Content != nil {
+			encoded = item.Content.Encoded
+		}
+		if cleanedDescription == "" {
+			cleanedDescription = utils.PlainText(encoded)
+		}
+		if cleanedDescription == "" {
+			cleanedDescription = summary
+		}
+		creator := item.Author
+		if creator == "" {
+			creator = item.DublinCore.Creator
+		}
+		guid := item.About
+		if item.GUID != nil {
+			guid = item.GUID.Value
+		}
+		pubTime, approximate := itemDate(itemKey(guid, item.Link, item.Title), item.Title,
+			[]string{item.PubDate, item.DublinCore.Date},
+			[]string{rss.LastBuildDate, rss.PubDate})
+		var enclosures []mediaFile
+		if item.Enclosure != nil {
+			enclosures = append(enclosures, mediaFile{URL: item.Enclosure.URL, Type: item.Enclosure.Type})
+		}
+		markup := encoded + item.Description
+		base := item.Link
+		if base == "" {
+			base = rss.Link
+		}
+		content := encoded
+		if content == "" {
+			content = item.Description
+		}
+		media := item.Media
+		if image := itunesImageURL(item.ITunes.Image); image != "" {
+			media.Thumbnails = append([]MediaThumbnail{{URL: image}}, media.Thumbnails...)
+		}
+		mediaURL, mediaType, thumbnail := itemMedia(enclosures, media, markup, base)
+		if thumbnail == "" && strings.HasPrefix(mediaType, "audio/") {
+			thumbnail = itunesImageURL(rss.ITunesImage)
 		}
+		episode, _ := strconv.Atoi(strings.TrimSpace(item.ITunes.Episode))
+		season, _ := strconv.Atoi(strings.TrimSpace(item.ITunes.Season))
 		newsItems = append(newsItems, models.NewsItem{
-			Title:        item.Title,
-			Description:  template.HTML(cleanedDescription),
-			ChannelLink:  rss.Link,
-			PubDate:      pubTime,
-			Creator:      item.Author,
-			Comments:     item.Comments,
-			Guid:         item.GUID.Value,
-			ItemLink:     item.Link,
-			ChannelTitle: rss.Title,
-			Category:     category,
-			Favicon:      utils.GetFaviconURL(rss.Link),
+			Title:           item.Title,
+			Description:     cleanedDescription,
+			ChannelLink:     rss.Link,
+			PubDate:         pubTime,
+			DateApproximate: approximate,
+			Content:         utils.SanitizeHTML(content, base),
+			MediaURL:        mediaURL,
+			MediaType:       mediaType,
+			Thumbnail:       thumbnail,
+			Duration:        parseITunesDuration(item.ITunes.Duration),
+			Episode:         episode,
+			Season:          season,
+			Explicit:        parseITunesExplicit(item.ITunes.Explicit),
+			Summary:         summary,
+			Creator:         creator,
+			Comments:        item.Comments,
+			Guid:            guid,
+			ItemLink:        item.Link,
+			ChannelTitle:    rss.Title,
+			Category:        category,
+			Favicon:         utils.GetFaviconURL(rss.Link),
 		})
 	}
 
-	return newsItems
+	return &rss, newsItems, nil
 }
 
 func ExtractLink(data []byte) string {
-	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	decoder := newXMLDecoder(data)
 
 	var (
 		mainLink  string
@@ -0,0 +1,117 @@
+package fetcher
+
+import (
+	"news-aggregator/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultInterval = 30 * time.Minute
+	MinTTLInterval  = 5 * time.Minute
+)
+
+type scheduleHints struct {
+	TTL       time.Duration
+	SkipHours map[int]bool
+	SkipDays  map[time.Weekday]bool
+}
+
+var (
+	feedHints = make(map[string]scheduleHints)
+	nextFetch = make(map[string]time.Time)
+	hintsMu   sync.Mutex
+)
+
+// rememberSchedule stores the ttl, skipHours and skipDays values announced
+// by an RSS channel.
+func rememberSchedule(feedURL string, rss *RSS) {
+	hints := scheduleHints{
+		TTL:       time.Duration(rss.TTL) * time.Minute,
+		SkipHours: make(map[int]bool),
+		SkipDays:  make(map[time.Weekday]bool),
+	}
+	if rss.SkipHours != nil {
+		for _, hour := range rss.SkipHours.Hours {
+			// Some feeds use 24 for midnight.
+			hints.SkipHours[hour%24] = true
+		}
+	}
+	if rss.SkipDays != nil {
+		for _, day := range rss.SkipDays.Days {
+			if weekday, ok := parseWeekday(day); ok {
+				hints.SkipDays[weekday] = true
+			}
+		}
+	}
+
+	hintsMu.Lock()
+	feedHints[feedURL] = hints
+	hintsMu.Unlock()
+}
+
+// Due reports whether the feed should be fetched at now. Disabled and dead
+// feeds are never due.
+func Due(feed config.FeedConfig, now time.Time) bool {
+	if !feed.Enabled || IsDead(feed.URL) {
+		return false
+	}
+	hintsMu.Lock()
+	defer hintsMu.Unlock()
+	return !now.Before(nextFetch[feed.URL])
+}
+
+// ScheduleNext stores and returns the next fetch time of the feed.
+func ScheduleNext(feed config.FeedConfig, now time.Time) time.Time {
+	next := NextFetch(feed, now)
+	hintsMu.Lock()
+	nextFetch[feed.URL] = next
+	hintsMu.Unlock()
+	return next
+}
+
+// NextFetch returns the time the feed should be fetched again. The interval
+// comes from the feed config, then from the channel ttl, then falls back to
+// DefaultInterval, and grows exponentially while the feed keeps failing.
+// Times inside the channel's skipHours/skipDays (GMT) are moved to the first
+// hour outside of them.
+func NextFetch(feed config.FeedConfig, now time.Time) time.Time {
+	hintsMu.Lock()
+	hints := feedHints[feed.URL]
+	hintsMu.Unlock()
+
+	interval := feed.Interval
+	if interval <= 0 && hints.TTL > 0 {
+		interval = hints.TTL
+		if interval < MinTTLInterval {
+			interval = MinTTLInterval
+		}
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	interval = backoff(feed.URL, interval)
+
+	next := now.Add(interval)
+	// A week of skipped hours means the channel asks never to be polled;
+	// give up on the skip windows in that case.
+	for i := 0; i < 7*24; i++ {
+		utc := next.UTC()
+		if !hints.SkipHours[utc.Hour()] && !hints.SkipDays[utc.Weekday()] {
+			return next
+		}
+		next = utc.Truncate(time.Hour).Add(time.Hour).In(now.Location())
+	}
+	return now.Add(interval)
+}
+
+func parseWeekday(day string) (time.Weekday, bool) {
+	day = strings.ToLower(strings.TrimSpace(day))
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.ToLower(weekday.String()) == day {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
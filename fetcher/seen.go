@@ -0,0 +1,119 @@
+package fetcher
+
+import (
+	"log"
+	"news-aggregator/models"
+	"news-aggregator/utils"
+	"sync"
+	"time"
+)
+
+// SeenRetention is how long first-seen times are kept after an item was
+// last looked up.
+var SeenRetention = 30 * 24 * time.Hour
+
+type seenEntry struct {
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+var (
+	seenItems = make(map[string]seenEntry)
+	seenMu    sync.Mutex
+)
+
+// itemKey identifies an item for first-seen bookkeeping. The parsers pass
+// the values they store in Guid, ItemLink and Title, so that touchSeen can
+// rebuild the key from a parsed item.
+func itemKey(guid, link, title string) string {
+	if guid != "" {
+		return guid
+	}
+	if link != "" {
+		return link
+	}
+	return title
+}
+
+// firstSeen returns the time key was first looked up, remembering now if it
+// is new.
+func firstSeen(key string) time.Time {
+	seenMu.Lock()
+	defer seenMu.Unlock()
+
+	now := time.Now()
+	entry, ok := seenItems[key]
+	if !ok {
+		entry.FirstSeen = now
+	}
+	entry.LastSeen = now
+	seenItems[key] = entry
+	return entry.FirstSeen
+}
+
+// touchSeen keeps the first-seen times of items that were not parsed again,
+// e.g. because their feed answered 304 Not Modified, from expiring.
+func touchSeen(items []models.NewsItem) {
+	seenMu.Lock()
+	defer seenMu.Unlock()
+
+	now := time.Now()
+	for _, item := range items {
+		key := itemKey(item.Guid, item.ItemLink, item.Title)
+		if entry, ok := seenItems[key]; ok {
+			entry.LastSeen = now
+			seenItems[key] = entry
+		}
+	}
+}
+
+// itemDate returns the first of itemDates that parses. When none does, it
+// falls back to channelDates and then to the time the item was first seen;
+// both fallbacks are reported as approximate.
+func itemDate(key, title string, itemDates, channelDates []string) (time.Time, bool) {
+	for _, dateStr := range itemDates {
+		if t, err := utils.FormatDate(dateStr); err == nil {
+			return t, false
+		}
+	}
+	for _, dateStr := range channelDates {
+		if t, err := utils.FormatDate(dateStr); err == nil {
+			return t, true
+		}
+	}
+	log.Printf("No usable date for item '%s', using the time it was first seen", title)
+	return firstSeen(key), true
+}
+
+// LoadSeen restores first-seen times saved by SaveSeen.
+func LoadSeen(filename string) error {
+	entries := make(map[string]seenEntry)
+	if err := loadJSON(filename, &entries); err != nil {
+		return err
+	}
+
+	seenMu.Lock()
+	defer seenMu.Unlock()
+	for key, entry := range entries {
+		seenItems[key] = entry
+	}
+	return nil
+}
+
+// SaveSeen writes the first-seen times, dropping entries that were not
+// looked up for SeenRetention.
+func SaveSeen(filename string) error {
+	seenMu.Lock()
+	cutoff := time.Now().Add(-SeenRetention)
+	snapshot := make(map[string]seenEntry, len(seenItems))
+	for key, entry := range seenItems {
+		if entry.LastSeen.Before(cutoff) {
+			delete(seenItems, key)
+			continue
+		}
+		snapshot[key] = entry
+	}
+	seenMu.Unlock()
+
+	return saveJSON(filename, snapshot)
+}
@@ -6,17 +6,37 @@ import (
 )
 
 type NewsItem struct {
-	Title        string        `json:"title"`
-	Description  template.HTML `json:"description"`
-	ChannelLink  string        `json:"channelLink"`
-	PubDate      time.Time     `json:"pubDate"`
-	Content      template.HTML `json:"content"`
-	MediaURL     string        `json:"mediaURL"`
-	Creator      string        `json:"creator"`
-	Comments     string        `json:"comments"`
-	Guid         string        `json:"guid"`
-	ItemLink     string        `json:"itemLink"`
-	ChannelTitle string        `json:"channelTitle"`
-	Category     string        `json:"category"`
-	Favicon      string        `json:"favicon"`
+	ID              string        `json:"id"`
+	Title           string        `json:"title"`
+	Description     string        `json:"description"`
+	ChannelLink     string        `json:"channelLink"`
+	PubDate         time.Time     `json:"pubDate"`
+	DateApproximate bool          `json:"dateApproximate"`
+	Content         template.HTML `json:"content"`
+	MediaURL        string        `json:"mediaURL"`
+	MediaType       string        `json:"mediaType"`
+	Thumbnail       string        `json:"thumbnail"`
+	Duration        time.Duration `json:"duration"`
+	Episode         int           `json:"episode"`
+	Season          int           `json:"season"`
+	Explicit        bool          `json:"explicit"`
+	Summary         string        `json:"summary"`
+	Creator         string        `json:"creator"`
+	Comments        string        `json:"comments"`
+	Guid            string        `json:"guid"`
+	ItemLink        string        `json:"itemLink"`
+	ChannelTitle    string        `json:"channelTitle"`
+	Category        string        `json:"category"`
+	Favicon         string        `json:"favicon"`
+	FeedURL         string        `json:"feedURL"`
+	ClusterID       string        `json:"clusterId"`
+}
+
+// StoryCluster groups items from different sources that cover the same
+// story. Items includes Lead.
+type StoryCluster struct {
+	ID      string     `json:"id"`
+	Lead    NewsItem   `json:"lead"`
+	Items   []NewsItem `json:"items"`
+	Sources int        `json:"sources"`
 }
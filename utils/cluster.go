@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"news-aggregator/models"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ClusterWindow is how far apart two items may be published and still be
+// the same story. ClusterThreshold is the similarity at which they are.
+var (
+	ClusterWindow    = 48 * time.Hour
+	ClusterThreshold = 0.35
+)
+
+const (
+	stemLength        = 6
+	descriptionTokens = 30
+)
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "that": true,
+	"this": true, "after": true, "over": true, "says": true, "said": true,
+	"will": true, "have": true, "has": true, "are": true, "was": true, "were": true,
+	"its": true, "into": true, "about": true, "new": true, "not": true, "but": true,
+	"you": true, "his": true, "her": true, "they": true, "their": true, "who": true,
+	"how": true, "what": true, "why": true, "when": true, "more": true, "than": true,
+	"как": true, "что": true, "это": true, "для": true, "после": true, "при": true,
+	"его": true, "она": true, "они": true, "который": true, "которые": true,
+	"также": true, "уже": true, "был": true, "была": true, "были": true,
+	"der": true, "die": true, "das": true, "und": true, "mit": true, "von": true,
+	"für": true, "nach": true, "les": true, "des": true, "une": true, "pour": true,
+}
+
+// storyShingles holds the word shingles of an item's title and lead.
+type storyShingles struct {
+	title       map[string]bool
+	description map[string]bool
+}
+
+// shingles lower-cases text, drops short and stop words and cuts words to
+// a common prefix, which is a cheap stemmer that works across languages.
+func shingles(text string, limit int) map[string]bool {
+	set := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		if limit > 0 && i >= limit {
+			break
+		}
+		if stopWords[word] || utf8.RuneCountInString(word) < 3 && !isDigits(word) {
+			continue
+		}
+		if runes := []rune(word); len(runes) > stemLength {
+			word = string(runes[:stemLength])
+		}
+		set[word] = true
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for s := range a {
+		if b[s] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// storySimilarity weighs titles over descriptions, which are often just
+// the outlet's boilerplate.
+func storySimilarity(a, b storyShingles) float64 {
+	title := jaccard(a.title, b.title)
+	if len(a.description) == 0 || len(b.description) == 0 {
+		return title
+	}
+	return 0.7*title + 0.3*jaccard(a.description, b.description)
+}
+
+// AssignClusters sets ClusterID on items that cover the same story. Items
+// are compared with every member of the clusters they share a title word
+// with, published within ClusterWindow of each other; the cluster ID is the
+// ID of its earliest item.
+func AssignClusters(items []models.NewsItem) {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return items[order[a]].PubDate.Before(items[order[b]].PubDate)
+	})
+
+	features := make([]storyShingles, len(items))
+	var clusters [][]int
+	index := make(map[string][]int)
+
+	for _, i := range order {
+		features[i] = storyShingles{
+			title:       shingles(items[i].Title, 0),
+			description: shingles(items[i].Description, descriptionTokens),
+		}
+
+		best, bestScore := -1, ClusterThreshold
+		if len(features[i].title) >= 2 {
+			checked := make(map[int]bool)
+			for s := range features[i].title {
+				for _, c := range index[s] {
+					if checked[c] {
+						continue
+					}
+					checked[c] = true
+					for _, j := range clusters[c] {
+						if items[i].PubDate.Sub(items[j].PubDate) > ClusterWindow {
+							continue
+						}
+						if score := storySimilarity(features[i], features[j]); score >= bestScore {
+							best, bestScore = c, score
+						}
+					}
+				}
+			}
+		}
+
+		if best < 0 {
+			best = len(clusters)
+			clusters = append(clusters, nil)
+		}
+		clusters[best] = append(clusters[best], i)
+		items[i].ClusterID = items[clusters[best][0]].ID
+		for s := range features[i].title {
+			if list := index[s]; len(list) == 0 || list[len(list)-1] != best {
+				index[s] = append(list, best)
+			}
+		}
+	}
+}
+
+// GroupClusters groups items by ClusterID, keeping the order of items: a
+// story is placed where its first item would be. The lead is the story's
+// earliest item when it is in items.
+func GroupClusters(items []models.NewsItem) []models.StoryCluster {
+	var clusters []models.StoryCluster
+	position := make(map[string]int)
+	for _, item := range items {
+		key := item.ClusterID
+		if key == "" {
+			key = item.ID
+		}
+		i, ok := position[key]
+		if !ok {
+			i = len(clusters)
+			position[key] = i
+			clusters = append(clusters, models.StoryCluster{ID: key, Lead: item})
+		}
+		clusters[i].Items = append(clusters[i].Items, item)
+		if item.ID == key {
+			clusters[i].Lead = item
+		}
+	}
+
+	for i := range clusters {
+		sources := make(map[string]bool)
+		for _, item := range clusters[i].Items {
+			sources[item.ChannelLink] = true
+		}
+		clusters[i].Sources = len(sources)
+	}
+	return clusters
+}
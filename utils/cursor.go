@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"news-aggregator/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in a list ordered by SortByDirection. Items are
+// ordered by PubDate and then by ID, so a cursor stays valid while new
+// items arrive.
+type Cursor struct {
+	PubDate time.Time
+	ID      string
+}
+
+func CursorOf(item models.NewsItem) Cursor {
+	return Cursor{PubDate: item.PubDate, ID: item.ID}
+}
+
+// Encode returns the cursor as an opaque URL-safe string.
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.PubDate.UnixNano(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{PubDate: time.Unix(0, n).UTC(), ID: id}, nil
+}
+
+// precedes reports whether a comes before b in sortFilter order.
+func (c Cursor) precedes(b Cursor, sortFilter string) bool {
+	if !c.PubDate.Equal(b.PubDate) {
+		if sortFilter == "asc" {
+			return c.PubDate.Before(b.PubDate)
+		}
+		return c.PubDate.After(b.PubDate)
+	}
+	if sortFilter == "asc" {
+		return c.ID < b.ID
+	}
+	return c.ID > b.ID
+}
+
+// Page returns up to limit items of items, which are ordered by
+// SortByDirection, that come after the cursor; an empty cursor starts at
+// the first item. next is the cursor of the following page, or "" when
+// this is the last one.
+func Page(items []models.NewsItem, cursor string, limit int, sortFilter string) (page []models.NewsItem, next string, err error) {
+	start, end, next, err := pageBounds(len(items), func(i int) Cursor {
+		return CursorOf(items[i])
+	}, cursor, limit, sortFilter)
+	if err != nil {
+		return nil, "", err
+	}
+	return items[start:end], next, nil
+}
+
+// PageClusters pages stories the way Page pages items. Clusters must come
+// from GroupClusters, so that each story is positioned by its first item.
+func PageClusters(clusters []models.StoryCluster, cursor string, limit int, sortFilter string) (page []models.StoryCluster, next string, err error) {
+	start, end, next, err := pageBounds(len(clusters), func(i int) Cursor {
+		return CursorOf(clusters[i].Items[0])
+	}, cursor, limit, sortFilter)
+	if err != nil {
+		return nil, "", err
+	}
+	return clusters[start:end], next, nil
+}
+
+func pageBounds(n int, key func(int) Cursor, cursor string, limit int, sortFilter string) (start, end int, next string, err error) {
+	if cursor != "" {
+		after, err := DecodeCursor(cursor)
+		if err != nil {
+			return 0, 0, "", err
+		}
+		start = sort.Search(n, func(i int) bool {
+			return after.precedes(key(i), sortFilter)
+		})
+	}
+
+	end = n
+	if limit > 0 && start+limit < end {
+		end = start + limit
+		next = key(end - 1).Encode()
+	}
+	return start, end, next, nil
+}
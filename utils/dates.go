@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultLocation is used for dates that carry no zone. MaxFutureDrift is
+// how far ahead of now a date may be before it is rejected, so that a feed
+// with a broken clock can't pin itself to the top of the list.
+var (
+	DefaultLocation = time.UTC
+	MaxFutureDrift  = 24 * time.Hour
+)
+
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05.999999999-0700",
+	"2006-01-02T15:04-0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04 -0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/1/2 15:04:05 -0700",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	"2006/1/2",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 3:04:05 PM -0700",
+	"2 Jan 2006 3:04 PM -0700",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006 3:04:05 PM",
+	"2 Jan 2006 3:04 PM",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 Jan 2006",
+	"Jan 2 2006 15:04:05 -0700",
+	"Jan 2 2006 15:04 -0700",
+	"Jan 2 2006 3:04:05 PM -0700",
+	"Jan 2 2006 3:04 PM -0700",
+	"Jan 2 2006 15:04:05",
+	"Jan 2 2006 15:04",
+	"Jan 2 2006 3:04:05 PM",
+	"Jan 2 2006 3:04 PM",
+	"Jan 2 15:04:05 -0700 2006",
+	"Jan 2 15:04:05 2006",
+	"Jan 2 2006",
+	"2.1.2006 15:04:05 -0700",
+	"2.1.2006 15:04:05",
+	"2.1.2006 15:04",
+	"2.1.2006",
+}
+
+// monthNames maps lower-case month names and abbreviations in the languages
+// our feeds use to the English abbreviation time.Parse understands.
+var monthNames = map[string]string{}
+
+func init() {
+	months := [12][]string{
+		{"january", "jan", "января", "январь", "янв", "januar", "jän", "janvier", "janv", "enero", "ene", "gennaio", "gen", "janeiro", "січня", "styczeń", "stycznia", "sty"},
+		{"february", "feb", "февраля", "февраль", "фев", "февр", "februar", "février", "févr", "fevr", "febrero", "febbraio", "fevereiro", "fev", "лютого", "luty", "lutego", "lut"},
+		{"march", "mar", "марта", "март", "мар", "märz", "mär", "mars", "marzo", "março", "березня", "marzec", "marca"},
+		{"april", "apr", "апреля", "апрель", "апр", "avril", "avr", "abril", "abr", "aprile", "квітня", "kwiecień", "kwietnia", "kwi"},
+		{"may", "мая", "май", "mai", "mayo", "maggio", "mag", "maio", "травня", "maj", "maja"},
+		{"june", "jun", "июня", "июнь", "июн", "juni", "juin", "junio", "giugno", "giu", "junho", "червня", "czerwiec", "czerwca", "cze"},
+		{"july", "jul", "июля", "июль", "июл", "juli", "juillet", "juil", "julio", "luglio", "lug", "julho", "липня", "lipiec", "lipca", "lip"},
+		{"august", "aug", "августа", "август", "авг", "août", "aout", "agosto", "ago", "серпня", "sierpień", "sierpnia", "sie"},
+		{"september", "sep", "sept", "сентября", "сентябрь", "сен", "сент", "septembre", "septiembre", "setiembre", "settembre", "set", "setembro", "вересня", "wrzesień", "września", "wrz"},
+		{"october", "oct", "октября", "октябрь", "окт", "oktober", "okt", "octobre", "octubre", "ottobre", "ott", "outubro", "out", "жовтня", "październik", "października", "paź"},
+		{"november", "nov", "ноября", "ноябрь", "ноя", "нояб", "novembre", "noviembre", "novembro", "листопада", "listopad", "listopada", "lis"},
+		{"december", "dec", "декабря", "декабрь", "дек", "dezember", "dez", "décembre", "déc", "diciembre", "dic", "dicembre", "dezembro", "грудня", "grudzień", "grudnia", "gru"},
+	}
+	for i, names := range months {
+		english := time.Month(i + 1).String()[:3]
+		for _, name := range names {
+			monthNames[name] = english
+		}
+	}
+}
+
+// zoneOffsets resolves zone abbreviations. time.Parse only knows the
+// abbreviations of the local zone and silently treats the rest as UTC.
+var zoneOffsets = map[string]string{
+	"UT": "+0000", "UTC": "+0000", "GMT": "+0000", "Z": "+0000", "WET": "+0000",
+	"WEST": "+0100", "BST": "+0100", "CET": "+0100", "MET": "+0100", "WAT": "+0100",
+	"CEST": "+0200", "MEST": "+0200", "EET": "+0200", "SAST": "+0200", "CAT": "+0200", "IST": "+0530",
+	"EEST": "+0300", "MSK": "+0300", "EAT": "+0300", "TRT": "+0300", "AST": "-0400",
+	"MSD": "+0400", "SAMT": "+0400", "GST": "+0400", "YEKT": "+0500", "PKT": "+0500",
+	"OMST": "+0600", "KRAT": "+0700", "ICT": "+0700", "WIB": "+0700", "IRKT": "+0800",
+	"SGT": "+0800", "HKT": "+0800", "AWST": "+0800", "YAKT": "+0900", "JST": "+0900",
+	"KST": "+0900", "ACST": "+0930", "ACDT": "+1030", "VLAT": "+1000", "AEST": "+1000",
+	"AEDT": "+1100", "MAGT": "+1100", "NZST": "+1200", "NZDT": "+1300",
+	"ADT": "-0300", "ART": "-0300", "BRT": "-0300", "NST": "-0330", "NDT": "-0230", "CLT": "-0400",
+	"EST": "-0500", "EDT": "-0400", "CST": "-0600", "CDT": "-0500", "MST": "-0700",
+	"MDT": "-0600", "PST": "-0800", "PDT": "-0700", "AKST": "-0900", "AKDT": "-0800",
+	"HST": "-1000",
+}
+
+var (
+	gmtOffsetRe = regexp.MustCompile(`^(?:GMT|UTC)([+-])(\d{1,2})(?::?(\d{2}))?$`)
+	offsetRe    = regexp.MustCompile(`^([+-]\d{2}):?(\d{2})$`)
+)
+
+// FormatDate parses the many date formats found in feeds: RFC 822/1123
+// with or without weekday, seconds or leading zeros, ISO 8601 with or
+// without zone, named zones, localized month names, and trailing junk.
+// Dates without a zone are read in DefaultLocation.
+func FormatDate(dateStr string) (time.Time, error) {
+	dateStr = strings.TrimSpace(dateStr)
+	if dateStr == "" {
+		return time.Time{}, fmt.Errorf("empty date string")
+	}
+
+	tokens := normalizeDate(dateStr)
+	// Drop trailing tokens one by one so that "… +0300 (Moscow Standard
+	// Time)" and similar junk still parse.
+	for n := len(tokens); n > 0; n-- {
+		t, ok := parseDate(strings.Join(tokens[:n], " "))
+		if !ok {
+			continue
+		}
+		if t.After(time.Now().Add(MaxFutureDrift)) {
+			return time.Time{}, fmt.Errorf("date is too far in the future: %s", dateStr)
+		}
+		return t.Local(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date format: %s", dateStr)
+}
+
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, DefaultLocation); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// normalizeDate splits dateStr into tokens with the weekday removed, month
+// names translated to English and zones turned into numeric offsets.
+func normalizeDate(dateStr string) []string {
+	dateStr = strings.TrimRight(dateStr, ".,; ")
+	fields := strings.FieldsFunc(dateStr, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ','
+	})
+
+	weekday := leadingWeekday(dateStr, fields)
+	tokens := make([]string, 0, len(fields))
+	for i, field := range fields {
+		word := strings.ToLower(strings.TrimSuffix(field, "."))
+		if i == 0 && weekday {
+			continue
+		}
+		if english, ok := monthNames[word]; ok {
+			tokens = append(tokens, english)
+			continue
+		}
+		if isDigits(word) {
+			// German and Polish write the day as "17."
+			tokens = append(tokens, word)
+			continue
+		}
+		switch word {
+		case "am", "pm":
+			tokens = append(tokens, strings.ToUpper(word))
+			continue
+		}
+		if offset, ok := zoneOffsets[strings.ToUpper(field)]; ok && i > 0 {
+			tokens = append(tokens, offset)
+			continue
+		}
+		if m := gmtOffsetRe.FindStringSubmatch(strings.ToUpper(field)); m != nil {
+			minutes := m[3]
+			if minutes == "" {
+				minutes = "00"
+			}
+			tokens = append(tokens, fmt.Sprintf("%s%02s%s", m[1], m[2], minutes))
+			continue
+		}
+		if m := offsetRe.FindStringSubmatch(field); m != nil && i > 0 {
+			tokens = append(tokens, m[1]+m[2])
+			continue
+		}
+		// "12:00:00GMT" and "10:00Z" style times glued to their zone.
+		if clock, zone, ok := splitZone(field); ok {
+			tokens = append(tokens, clock, zone)
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+// leadingWeekday reports whether the first of fields, the words of
+// dateStr, is a weekday in whatever language. A month name is taken for a
+// weekday when a comma follows it or another month name comes later: "mar"
+// is both Tuesday and March in Spanish, Italian and French.
+func leadingWeekday(dateStr string, fields []string) bool {
+	if len(fields) == 0 {
+		return false
+	}
+	word := strings.ToLower(strings.TrimSuffix(fields[0], "."))
+	if !isLetters(word) {
+		return false
+	}
+	if _, ok := monthNames[word]; !ok {
+		return true
+	}
+	rest := strings.TrimLeftFunc(strings.TrimPrefix(dateStr, fields[0]), unicode.IsSpace)
+	if strings.HasPrefix(rest, ",") {
+		return true
+	}
+	for _, field := range fields[1:] {
+		if _, ok := monthNames[strings.ToLower(strings.TrimSuffix(field, "."))]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func splitZone(field string) (string, string, bool) {
+	if strings.Contains(field, "-") || !strings.Contains(field, ":") {
+		// ISO 8601 timestamps keep their zone attached.
+		return "", "", false
+	}
+	end := strings.LastIndexFunc(field, unicode.IsDigit)
+	if end < 0 || end == len(field)-1 {
+		return "", "", false
+	}
+	offset, ok := zoneOffsets[strings.ToUpper(field[end+1:])]
+	if !ok {
+		return "", "", false
+	}
+	return field[:end+1], offset, true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetters(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
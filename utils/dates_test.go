@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDate(t *testing.T) {
+	msk := time.FixedZone("", 3*3600)
+	edt := time.FixedZone("", -4*3600)
+	cet := time.FixedZone("", 3600)
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		// RFC 822/1123 and their variations.
+		{"Tue, 05 Mar 2024 10:00:00 +0100", time.Date(2024, 3, 5, 10, 0, 0, 0, cet)},
+		{"05 Mar 2024 10:00:00 +0100", time.Date(2024, 3, 5, 10, 0, 0, 0, cet)},
+		{"Tue, 5 Mar 2024 10:00:00 +0100", time.Date(2024, 3, 5, 10, 0, 0, 0, cet)},
+		{"Tue, 05 Mar 2024 10:00 +0100", time.Date(2024, 3, 5, 10, 0, 0, 0, cet)},
+		{"Tue, 05 Mar 24 10:00:00 +0100", time.Date(2024, 3, 5, 10, 0, 0, 0, cet)},
+		{"Tue, 05 Mar 2024 10:00:00 +01:00", time.Date(2024, 3, 5, 10, 0, 0, 0, cet)},
+		{"Mon, 4 Mar 2024 3:04:05 PM -0400", time.Date(2024, 3, 4, 15, 4, 5, 0, edt)},
+
+		// Named zones.
+		{"Tue, 05 Mar 2024 10:00:00 EDT", time.Date(2024, 3, 5, 10, 0, 0, 0, edt)},
+		{"Tue, 05 Mar 2024 10:00:00 MSK", time.Date(2024, 3, 5, 10, 0, 0, 0, msk)},
+		{"Tue, 05 Mar 2024 10:00:00 GMT", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{"Tue, 05 Mar 2024 10:00:00 gmt", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{"Tue, 05 Mar 2024 10:00:00 GMT+3", time.Date(2024, 3, 5, 10, 0, 0, 0, msk)},
+		{"Tue, 05 Mar 2024 10:00:00GMT", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+
+		// ISO 8601 and SQL-style dates.
+		{"2024-03-05T10:00:00Z", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{"2024-03-05T10:00:00.123+03:00", time.Date(2024, 3, 5, 10, 0, 0, 123e6, msk)},
+		{"2024-03-05T10:00:00+0300", time.Date(2024, 3, 5, 10, 0, 0, 0, msk)},
+		{"2024-03-05T10:00:00", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{"2024-03-05T10:00", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{"2024-03-05 10:00:00", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{"2024-03-05 10:00:00 +0300", time.Date(2024, 3, 5, 10, 0, 0, 0, msk)},
+		{"2024-03-05", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"2024/3/5 10:00", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+
+		// Month first.
+		{"Mar 5, 2024 10:00:00 +0100", time.Date(2024, 3, 5, 10, 0, 0, 0, cet)},
+		{"March 5, 2024", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"Tue Mar 5 10:00:00 +0100 2024", time.Date(2024, 3, 5, 10, 0, 0, 0, cet)},
+
+		// Localized month and weekday names.
+		{"Вт, 05 мар 2024 10:00:00 +0300", time.Date(2024, 3, 5, 10, 0, 0, 0, msk)},
+		{"5 марта 2024 10:00", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{"Di, 5. März 2024 10:00:00 +0100", time.Date(2024, 3, 5, 10, 0, 0, 0, cet)},
+		{"05.03.2024 10:00", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{"mardi 5 mars 2024 10:00", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{"5 févr. 2024", time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC)},
+		{"Mar, 05 Mar 2024 10:00:00 +0100", time.Date(2024, 3, 5, 10, 0, 0, 0, cet)},
+		{"mar. 5 mar. 2024 10:00", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{"Mié, 06 Dic 2023 10:00:00 +0100", time.Date(2023, 12, 6, 10, 0, 0, 0, cet)},
+		{"6 dicembre 2023", time.Date(2023, 12, 6, 0, 0, 0, 0, time.UTC)},
+
+		// Trailing junk.
+		{"Tue, 05 Mar 2024 10:00:00 +0300 (Moscow Standard Time)", time.Date(2024, 3, 5, 10, 0, 0, 0, msk)},
+		{"Tue, 05 Mar 2024 10:00:00 +0100.", time.Date(2024, 3, 5, 10, 0, 0, 0, cet)},
+		{"  2024-03-05T10:00:00Z  ", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := FormatDate(tt.input)
+		if err != nil {
+			t.Errorf("FormatDate(%q): %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("FormatDate(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDateErrors(t *testing.T) {
+	future := time.Now().Add(7 * 24 * time.Hour).UTC().Format(time.RFC1123Z)
+	for _, input := range []string{"", "   ", "yesterday", "32 Mar 2024", future} {
+		if got, err := FormatDate(input); err == nil {
+			t.Errorf("FormatDate(%q) = %v, want an error", input, got)
+		}
+	}
+}
+
+func TestFormatDateDefaultLocation(t *testing.T) {
+	defer func(loc *time.Location) { DefaultLocation = loc }(DefaultLocation)
+	DefaultLocation = time.FixedZone("", 3*3600)
+
+	got, err := FormatDate("2024-03-05 10:00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 5, 7, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// An explicit zone wins over DefaultLocation.
+	got, err = FormatDate("2024-03-05T10:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
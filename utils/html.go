@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"html"
+	"strings"
+)
+
+type htmlTokenType int
+
+const (
+	textToken htmlTokenType = iota
+	startTagToken
+	endTagToken
+)
+
+type htmlAttr struct {
+	Name  string
+	Value string
+}
+
+// htmlToken is a piece of an HTML document. Text and attribute values are
+// already entity-decoded.
+type htmlToken struct {
+	Type        htmlTokenType
+	Name        string
+	Attrs       []htmlAttr
+	SelfClosing bool
+	Text        string
+}
+
+func (t htmlToken) attr(name string) string {
+	for _, a := range t.Attrs {
+		if a.Name == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// rawTextElements hold text that must not be scanned for tags.
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true, "xmp": true,
+}
+
+// tokenizeHTML splits markup into text, start tag and end tag tokens.
+// Comments, doctypes and processing instructions are dropped. It is
+// forgiving in the way browsers are: a "<" that doesn't start a tag is text
+// and an unterminated tag is dropped.
+func tokenizeHTML(s string) []htmlToken {
+	var tokens []htmlToken
+	text := func(t string) {
+		if t != "" {
+			tokens = append(tokens, htmlToken{Type: textToken, Text: html.UnescapeString(t)})
+		}
+	}
+
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			text(s)
+			break
+		}
+		text(s[:i])
+		s = s[i:]
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s[4:], "-->")
+			if end < 0 {
+				return tokens
+			}
+			s = s[4+end+3:]
+		case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return tokens
+			}
+			s = s[end+1:]
+		case len(s) > 2 && s[1] == '/' && isASCIILetter(s[2]):
+			name, rest := scanTagName(s[2:])
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return tokens
+			}
+			tokens = append(tokens, htmlToken{Type: endTagToken, Name: name})
+			s = rest[end+1:]
+		case len(s) > 1 && isASCIILetter(s[1]):
+			tok, rest, ok := scanStartTag(s[1:])
+			if !ok {
+				return tokens
+			}
+			tokens = append(tokens, tok)
+			s = rest
+			if rawTextElements[tok.Name] && !tok.SelfClosing {
+				end := indexFold(s, "</"+tok.Name)
+				if end < 0 {
+					end = len(s)
+				}
+				if tok.Name == "textarea" || tok.Name == "title" {
+					text(s[:end])
+				} else if end > 0 {
+					tokens = append(tokens, htmlToken{Type: textToken, Text: s[:end]})
+				}
+				s = s[end:]
+			}
+		default:
+			text("<")
+			s = s[1:]
+		}
+	}
+	return tokens
+}
+
+func scanTagName(s string) (string, string) {
+	i := 0
+	for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '/' && s[i] != '>' {
+		i++
+	}
+	return strings.ToLower(s[:i]), s[i:]
+}
+
+// scanStartTag reads a start tag after its "<" and returns what follows the
+// closing ">".
+func scanStartTag(s string) (htmlToken, string, bool) {
+	tok := htmlToken{Type: startTagToken}
+	tok.Name, s = scanTagName(s)
+	for {
+		s = strings.TrimLeft(s, " \t\n\r\f")
+		if s == "" {
+			return tok, "", false
+		}
+		switch s[0] {
+		case '>':
+			return tok, s[1:], true
+		case '/':
+			s = s[1:]
+			if strings.HasPrefix(s, ">") {
+				tok.SelfClosing = true
+			}
+			continue
+		}
+
+		i := 0
+		for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '/' && s[i] != '>' && (s[i] != '=' || i == 0) {
+			i++
+		}
+		attr := htmlAttr{Name: strings.ToLower(s[:i])}
+		s = strings.TrimLeft(s[i:], " \t\n\r\f")
+		if strings.HasPrefix(s, "=") {
+			s = strings.TrimLeft(s[1:], " \t\n\r\f")
+			var value string
+			if s != "" && (s[0] == '"' || s[0] == '\'') {
+				end := strings.IndexByte(s[1:], s[0])
+				if end < 0 {
+					return tok, "", false
+				}
+				value, s = s[1:1+end], s[2+end:]
+			} else {
+				j := 0
+				for j < len(s) && !isHTMLSpace(s[j]) && s[j] != '>' {
+					j++
+				}
+				value, s = s[:j], s[j:]
+			}
+			attr.Value = html.UnescapeString(value)
+		}
+		tok.Attrs = append(tok.Attrs, attr)
+	}
+}
+
+func indexFold(s, substr string) int {
+	n := len(substr)
+	for i := 0; i+n <= len(s); i++ {
+		if strings.EqualFold(s[i:i+n], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,274 @@
+package utils
+
+import (
+	"html"
+	"html/template"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// htmlNode is an element or, when Tag is empty, a text node.
+type htmlNode struct {
+	Tag      string
+	Attrs    []htmlAttr
+	Text     string
+	Parent   *htmlNode
+	Children []*htmlNode
+}
+
+func (n *htmlNode) attr(name string) string {
+	return htmlToken{Attrs: n.Attrs}.attr(name)
+}
+
+// parseHTMLTree builds a loose element tree from markup. It is good enough
+// to score a page, not a conforming HTML parser.
+func parseHTMLTree(s string) *htmlNode {
+	root := &htmlNode{Tag: "#root"}
+	cur := root
+	for _, tok := range tokenizeHTML(s) {
+		switch tok.Type {
+		case textToken:
+			cur.Children = append(cur.Children, &htmlNode{Text: tok.Text, Parent: cur})
+		case startTagToken:
+			if closesImplicitly(cur.Tag, tok.Name) {
+				cur = cur.Parent
+			}
+			n := &htmlNode{Tag: tok.Name, Attrs: tok.Attrs, Parent: cur}
+			cur.Children = append(cur.Children, n)
+			if !voidTags[tok.Name] && !tok.SelfClosing {
+				cur = n
+			}
+		case endTagToken:
+			for n := cur; n != root; n = n.Parent {
+				if n.Tag == tok.Name {
+					cur = n.Parent
+					break
+				}
+			}
+		}
+	}
+	return root
+}
+
+// closesImplicitly reports whether opening next ends the open element.
+func closesImplicitly(open, next string) bool {
+	switch open {
+	case "p":
+		return blockTags[next]
+	case "li":
+		return next == "li"
+	case "td", "th":
+		return next == "td" || next == "th" || next == "tr"
+	case "dt", "dd":
+		return next == "dt" || next == "dd"
+	}
+	return false
+}
+
+// strippedTags never hold article text.
+var strippedTags = map[string]bool{
+	"aside": true, "button": true, "embed": true, "footer": true, "form": true,
+	"header": true, "iframe": true, "input": true, "nav": true, "noscript": true,
+	"object": true, "script": true, "select": true, "style": true, "svg": true,
+	"textarea": true, "head": true,
+}
+
+var (
+	unlikelyRe = regexp.MustCompile(`(?i)-ad-|ad-break|advert|agegate|banner|breadcrumb|combx|comment|community|cookie|disqus|footer|gdpr|header|menu|newsletter|pager|pagination|popup|promo|related|remark|replies|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|supplemental`)
+	maybeRe    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveRe = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativeRe = regexp.MustCompile(`(?i)hidden|banner|combx|comment|com-|contact|foot|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// ExtractArticle finds the main text of a web page the way Readability
+// does: navigation and boilerplate are pruned, paragraphs score their
+// ancestors by length and commas, and the best scoring block wins after a
+// penalty for link density. The result is sanitized with base for relative
+// URLs; it is empty when the page has no convincing article.
+func ExtractArticle(page, base string) template.HTML {
+	root := parseHTMLTree(page)
+	pruneTree(root)
+
+	scores := make(map[*htmlNode]float64)
+	score := func(n *htmlNode, points float64) {
+		if n == nil || n == root {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = tagWeight(n.Tag) + classWeight(n)
+		}
+		scores[n] += points
+	}
+
+	walkTree(root, func(n *htmlNode) {
+		switch n.Tag {
+		case "p", "pre", "td", "blockquote":
+		default:
+			return
+		}
+		text := strings.TrimSpace(nodeText(n))
+		length := utf8.RuneCountInString(text)
+		if length < 25 {
+			return
+		}
+		points := 1 + float64(strings.Count(text, ",")) + math.Min(float64(length)/100, 3)
+		score(n.Parent, points)
+		if n.Parent != nil {
+			score(n.Parent.Parent, points/2)
+		}
+	})
+
+	var best *htmlNode
+	bestScore := 0.0
+	for n, s := range scores {
+		s *= 1 - linkDensity(n)
+		scores[n] = s
+		if best == nil || s > bestScore {
+			best, bestScore = n, s
+		}
+	}
+	if best == nil {
+		return ""
+	}
+
+	// Siblings that score well or read like paragraphs belong to the
+	// article too, e.g. when it is split around an image or an ad slot.
+	var b strings.Builder
+	siblings := []*htmlNode{best}
+	if best.Parent != nil {
+		siblings = best.Parent.Children
+	}
+	threshold := math.Max(10, bestScore*0.2)
+	for _, sib := range siblings {
+		include := sib == best
+		if s, ok := scores[sib]; ok && s >= threshold {
+			include = true
+		} else if sib.Tag == "p" {
+			text := nodeText(sib)
+			include = utf8.RuneCountInString(text) > 80 && linkDensity(sib) < 0.25
+		}
+		if include {
+			renderNode(&b, sib)
+		}
+	}
+
+	content := SanitizeHTML(b.String(), base)
+	if utf8.RuneCountInString(PlainText(string(content))) < 200 {
+		return ""
+	}
+	return content
+}
+
+// pruneTree removes boilerplate elements and elements whose class or id
+// marks them as unlikely to be part of the article.
+func pruneTree(n *htmlNode) {
+	kept := n.Children[:0]
+	for _, c := range n.Children {
+		if c.Tag != "" && (strippedTags[c.Tag] || isUnlikely(c)) {
+			continue
+		}
+		pruneTree(c)
+		kept = append(kept, c)
+	}
+	n.Children = kept
+}
+
+func isUnlikely(n *htmlNode) bool {
+	switch n.Tag {
+	case "html", "body", "article", "main", "a":
+		return false
+	}
+	if role := n.attr("role"); role == "navigation" || role == "complementary" || role == "banner" {
+		return true
+	}
+	match := n.attr("class") + " " + n.attr("id")
+	return unlikelyRe.MatchString(match) && !maybeRe.MatchString(match)
+}
+
+func tagWeight(tag string) float64 {
+	switch tag {
+	case "article", "main":
+		return 10
+	case "div":
+		return 5
+	case "pre", "td", "blockquote":
+		return 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		return -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		return -5
+	}
+	return 0
+}
+
+func classWeight(n *htmlNode) float64 {
+	weight := 0.0
+	for _, value := range []string{n.attr("class"), n.attr("id")} {
+		if value == "" {
+			continue
+		}
+		if negativeRe.MatchString(value) {
+			weight -= 25
+		}
+		if positiveRe.MatchString(value) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+func walkTree(n *htmlNode, fn func(*htmlNode)) {
+	for _, c := range n.Children {
+		fn(c)
+		walkTree(c, fn)
+	}
+}
+
+func nodeText(n *htmlNode) string {
+	if n.Tag == "" {
+		return n.Text
+	}
+	var b strings.Builder
+	walkTree(n, func(c *htmlNode) {
+		if c.Tag == "" {
+			b.WriteString(c.Text)
+		}
+	})
+	return b.String()
+}
+
+// linkDensity is the share of n's text that sits inside links.
+func linkDensity(n *htmlNode) float64 {
+	total := utf8.RuneCountInString(nodeText(n))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	walkTree(n, func(c *htmlNode) {
+		if c.Tag == "a" {
+			links += utf8.RuneCountInString(nodeText(c))
+		}
+	})
+	return math.Min(float64(links)/float64(total), 1)
+}
+
+func renderNode(b *strings.Builder, n *htmlNode) {
+	if n.Tag == "" {
+		b.WriteString(html.EscapeString(n.Text))
+		return
+	}
+	b.WriteString("<" + n.Tag)
+	for _, a := range n.Attrs {
+		b.WriteString(" " + a.Name + `="` + html.EscapeString(a.Value) + `"`)
+	}
+	b.WriteString(">")
+	if voidTags[n.Tag] {
+		return
+	}
+	for _, c := range n.Children {
+		renderNode(b, c)
+	}
+	b.WriteString("</" + n.Tag + ">")
+}
@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// allowedTags lists the elements SanitizeHTML keeps, with the attributes
+// allowed on each. Other elements are unwrapped and keep their text.
+var allowedTags = map[string][]string{
+	"a": {"href", "title"}, "abbr": {"title"}, "b": nil, "blockquote": {"cite"},
+	"br": nil, "caption": nil, "cite": nil, "code": nil, "dd": nil, "del": nil,
+	"details": nil, "dfn": nil, "div": nil, "dl": nil, "dt": nil, "em": nil,
+	"figcaption": nil, "figure": nil, "h1": nil, "h2": nil, "h3": nil, "h4": nil,
+	"h5": nil, "h6": nil, "hr": nil, "i": nil, "img": {"src", "alt", "title", "width", "height"},
+	"ins": nil, "kbd": nil, "li": nil, "mark": nil, "ol": {"start"}, "p": nil,
+	"pre": nil, "q": {"cite"}, "s": nil, "samp": nil, "small": nil, "span": nil,
+	"strike": nil, "strong": nil, "sub": nil, "summary": nil, "sup": nil,
+	"table": nil, "tbody": nil, "td": {"colspan", "rowspan"}, "tfoot": nil,
+	"th": {"colspan", "rowspan"}, "thead": nil, "time": {"datetime"}, "tr": nil,
+	"u": nil, "ul": nil,
+	"audio": {"src", "controls"}, "video": {"src", "poster", "controls", "width", "height"},
+	"source": {"src", "type"},
+}
+
+// droppedTags are removed together with everything inside them.
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "template": true, "noscript": true,
+	"iframe": true, "object": true, "embed": true, "applet": true,
+	"frameset": true, "frame": true, "textarea": true, "select": true,
+	"button": true, "svg": true, "math": true, "head": true, "title": true,
+}
+
+var voidTags = map[string]bool{
+	"br": true, "hr": true, "img": true, "source": true, "wbr": true,
+	"input": true, "meta": true, "link": true, "area": true, "base": true,
+	"col": true, "embed": true, "param": true, "track": true,
+}
+
+var urlAttrs = map[string]bool{"href": true, "src": true, "cite": true, "poster": true}
+
+// SanitizeHTML keeps only allow-listed elements and attributes of input.
+// Scripts, styles and embedded frames are removed, relative URLs are
+// resolved against base, links open in a new tab and unclosed elements are
+// closed so the result can't break the page it is embedded in.
+func SanitizeHTML(input, base string) template.HTML {
+	baseURL, _ := url.Parse(base)
+
+	var out strings.Builder
+	var open []string
+	skip := ""
+	skipDepth := 0
+
+	for _, tok := range tokenizeHTML(input) {
+		if skip != "" {
+			if tok.Name == skip && !voidTags[tok.Name] {
+				if tok.Type == startTagToken && !tok.SelfClosing {
+					skipDepth++
+				} else if tok.Type == endTagToken {
+					skipDepth--
+				}
+			}
+			if skipDepth == 0 {
+				skip = ""
+			}
+			continue
+		}
+
+		switch tok.Type {
+		case textToken:
+			out.WriteString(html.EscapeString(tok.Text))
+
+		case startTagToken:
+			if droppedTags[tok.Name] {
+				if !tok.SelfClosing && !voidTags[tok.Name] {
+					skip, skipDepth = tok.Name, 1
+				}
+				continue
+			}
+			attrs, ok := allowedTags[tok.Name]
+			if !ok {
+				continue
+			}
+			tag, ok := sanitizeTag(tok, attrs, baseURL)
+			if !ok {
+				continue
+			}
+			out.WriteString(tag)
+			if !voidTags[tok.Name] {
+				open = append(open, tok.Name)
+			}
+
+		case endTagToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != tok.Name {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					out.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		out.WriteString("</" + open[i] + ">")
+	}
+
+	return template.HTML(strings.TrimSpace(out.String()))
+}
+
+func sanitizeTag(tok htmlToken, allowed []string, base *url.URL) (string, bool) {
+	var b strings.Builder
+	b.WriteString("<" + tok.Name)
+	hasSrc := false
+	for _, a := range tok.Attrs {
+		if !contains(allowed, a.Name) {
+			continue
+		}
+		value := a.Value
+		if urlAttrs[a.Name] {
+			var ok bool
+			if value, ok = safeURL(value, base); !ok {
+				continue
+			}
+		}
+		b.WriteString(" " + a.Name + `="` + html.EscapeString(value) + `"`)
+		hasSrc = hasSrc || a.Name == "src" && value != ""
+	}
+	switch tok.Name {
+	case "a":
+		b.WriteString(` target="_blank" rel="noopener"`)
+	case "img":
+		// An image whose source was missing or unsafe shows nothing.
+		if !hasSrc {
+			return "", false
+		}
+		b.WriteString(` loading="lazy"`)
+	}
+	b.WriteString(">")
+	return b.String(), true
+}
+
+// safeURL resolves ref against base and accepts only web and mail links.
+func safeURL(ref string, base *url.URL) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return u.String(), true
+	case "":
+		// Fragment or path without a base to resolve against.
+		return u.String(), !strings.Contains(ref, ":")
+	}
+	return "", false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// blockTags separate words when markup is flattened to text.
+var blockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"br": true, "dd": true, "div": true, "dl": true, "dt": true,
+	"figcaption": true, "figure": true, "footer": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "td": true, "th": true, "tr": true, "ul": true,
+}
+
+// PlainText flattens HTML to text: tags are removed, entities decoded and
+// whitespace collapsed. The result is plain text, not HTML, and must be
+// escaped before it is written into a page.
+func PlainText(input string) string {
+	var b strings.Builder
+	skip := ""
+	skipDepth := 0
+	for _, tok := range tokenizeHTML(input) {
+		switch {
+		case skip != "":
+			if tok.Name == skip && tok.Type == startTagToken && !tok.SelfClosing {
+				skipDepth++
+			} else if tok.Name == skip && tok.Type == endTagToken {
+				skipDepth--
+			}
+			if skipDepth == 0 {
+				skip = ""
+			}
+		case tok.Type == textToken:
+			b.WriteString(tok.Text)
+		case tok.Type == startTagToken && droppedTags[tok.Name] && !tok.SelfClosing && !voidTags[tok.Name]:
+			skip, skipDepth = tok.Name, 1
+		case blockTags[tok.Name]:
+			b.WriteByte(' ')
+		}
+	}
+	return strings.Join(strings.FieldsFunc(b.String(), unicode.IsSpace), " ")
+}
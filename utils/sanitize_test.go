@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	const base = "https://example.com/a/"
+	tests := []struct {
+		name, input, want string
+	}{
+		{"javascript mixed case", `<a href="JaVaScRiPt:alert(1)">x</a>`, `<a target="_blank" rel="noopener">x</a>`},
+		{"javascript decimal entity", `<a href="&#106;avascript:alert(1)">x</a>`, `<a target="_blank" rel="noopener">x</a>`},
+		{"javascript named entities", `<a href="&#x6A;avascript&colon;alert(1)">x</a>`, `<a target="_blank" rel="noopener">x</a>`},
+		{"javascript encoded tab", `<a href="java&#x09;script:alert(1)">x</a>`, `<a target="_blank" rel="noopener">x</a>`},
+		{"javascript padded", `<a href="  javascript:alert(1)  ">x</a>`, `<a target="_blank" rel="noopener">x</a>`},
+		{"javascript newline", "<a href=\"\njavascript:alert(1)\">x</a>", `<a target="_blank" rel="noopener">x</a>`},
+		{"data link", `<a href="data:text/html,<script>alert(1)</script>">x</a>`, `<a target="_blank" rel="noopener">x</a>`},
+		{"data image", `<img src="data:image/svg+xml;base64,PHN2Zz4=">`, ``},
+		{"vbscript image", `<img src="vbscript:msgbox(1)" alt="a">`, ``},
+		{"relative and mailto", `<a href="../b">b</a><a href="mailto:x@example.com">m</a>`, `<a href="https://example.com/b" target="_blank" rel="noopener">b</a><a href="mailto:x@example.com" target="_blank" rel="noopener">m</a>`},
+		{"quoted >", `<a title="a>b" href="/x">y</a>`, `<a title="a&gt;b" href="https://example.com/x" target="_blank" rel="noopener">y</a>`},
+		{"quoted > breaking out", `<img alt="x><script>alert(1)</script>" src="i.png">`, `<img alt="x&gt;&lt;script&gt;alert(1)&lt;/script&gt;" src="https://example.com/a/i.png" loading="lazy">`},
+		{"event handlers", `<img src=x onerror=alert(1)><p onclick="alert(1)" style="x">t</p>`, `<img src="https://example.com/a/x" loading="lazy"><p>t</p>`},
+		{"unclosed", `<b><i>x`, `<b><i>x</i></b>`},
+		{"stray end tags", `</div></b>x</p>`, `x`},
+		{"misnested", `<b><i>x</b>y</i>`, `<b><i>x</i></b>y`},
+		{"unknown tags unwrapped", `<article><font color="red">x</font></article>`, `x`},
+		{"script with </p>", `<script>var s="</p><img src=x onerror=alert(1)>"</script>ok`, `ok`},
+		{"style with </p>", `<style>p{}</p><img src=x onerror=alert(1)></style>ok`, `ok`},
+		{"unterminated script", `ok<script>alert(1)`, `ok`},
+		{"nested dropped", `<object><object></object><img src=x></object>ok`, `ok`},
+		{"svg", `<svg><script>alert(1)</script><a href="javascript:x">t</a></svg>ok`, `ok`},
+		{"math", `<math><mtext><img src=x onerror=alert(1)></mtext></math>ok`, `ok`},
+		{"iframe", `<iframe src="https://evil.example/"></iframe>ok`, `ok`},
+		{"unclosed comment", `a<!-- <script>alert(1)</script>`, `a`},
+		{"comment", `a<!-- x --><b>b</b>`, `a<b>b</b>`},
+		{"unterminated tag", `a<img src="x`, `a`},
+		{"text escaped", `1 < 2 &amp; 3 > 2`, `1 &lt; 2 &amp; 3 &gt; 2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(SanitizeHTML(tt.input, base))
+			if got != tt.want {
+				t.Errorf("SanitizeHTML(%q)\n got %q\nwant %q", tt.input, got, tt.want)
+			}
+			if lower := strings.ToLower(got); strings.Contains(lower, "javascript:") || strings.Contains(lower, "<script") {
+				t.Errorf("SanitizeHTML(%q) = %q is unsafe", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"entities", `Tom &amp; Jerry &lt;b&gt; caf&eacute; &#233;&#x41;`, `Tom & Jerry <b> café éA`},
+		{"tags removed", `<p>one</p><p>two <b>bold</b></p>`, `one two bold`},
+		{"dropped content", `a<script>alert("</p>")</script><style>p{}</style>b`, `ab`},
+		{"nested dropped", `a<svg><svg>x</svg>y</svg>b`, `ab`},
+		{"whitespace", "  a\n\t b  ", `a b`},
+		{"unclosed comment", `a<!-- b`, `a`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlainText(tt.input); got != tt.want {
+				t.Errorf("PlainText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
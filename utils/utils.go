@@ -2,7 +2,6 @@ package utils
 
 import (
 	"fmt"
-	"html/template"
 	"io"
 	"net/http"
 	"net/url"
@@ -10,6 +9,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,46 +19,27 @@ type UniqueItemsResult struct {
 	FaviconURLs map[string]string
 }
 
-func StripHTMLTags(html string) string {
-	var result strings.Builder
-	var inTag bool
-
-	for _, char := range html {
-		if char == '<' {
-			inTag = true
-		} else if char == '>' {
-			inTag = false
-		} else if !inTag {
-			result.WriteRune(char)
-		}
-	}
-	return result.String()
+type CategoryCount struct {
+	Name    string `json:"name"`
+	Count   int    `json:"count"`
+	Sources int    `json:"sources"`
 }
 
-func FormatDate(dateStr string) (time.Time, error) {
-	dateStr = strings.TrimSpace(dateStr)
-	if dateStr == "" {
-		return time.Time{}, fmt.Errorf("empty date string")
-	}
-
-	formats := []string{
-		time.RFC3339,
-		time.RFC1123Z,
-		time.RFC1123,
-		time.RFC822,
-		time.RFC822Z,
-		"Mon, 02 Jan 2006 15:04:05 -0700",
-		"Mon, 02 Jan 2006 15:04:05 +0000",
-	}
-
-	for _, format := range formats {
-		t, err := time.Parse(format, dateStr)
-		if err == nil {
-			return t.Local(), nil
-		}
-	}
+// Source is a channel with the number of items it has in a list.
+type Source struct {
+	Title    string `json:"title"`
+	Link     string `json:"link"`
+	Category string `json:"category"`
+	Favicon  string `json:"favicon"`
+	FeedURL  string `json:"feedURL"`
+	Count    int    `json:"count"`
+}
 
-	return time.Time{}, fmt.Errorf("unrecognized date format: %s", dateStr)
+// CategoryGroup is a category with its sources, busiest first.
+type CategoryGroup struct {
+	Name    string   `json:"name"`
+	Count   int      `json:"count"`
+	Sources []Source `json:"sources"`
 }
 
 func FilterNewsByTime(newsItems []models.NewsItem, timeFilter time.Duration, sortFilter string) []models.NewsItem {
@@ -78,11 +59,9 @@ func FilterNewsByTime(newsItems []models.NewsItem, timeFilter time.Duration, sor
 }
 
 func SortByDirection(filteredItems []models.NewsItem, timeFilter time.Duration, sortFilter string) []models.NewsItem {
+	// Equal dates are ordered by ID so that pages of the list are stable.
 	sort.Slice(filteredItems, func(i, j int) bool {
-		if sortFilter == "asc" {
-			return filteredItems[i].PubDate.Before(filteredItems[j].PubDate)
-		}
-		return filteredItems[i].PubDate.After(filteredItems[j].PubDate)
+		return CursorOf(filteredItems[i]).precedes(CursorOf(filteredItems[j]), sortFilter)
 	})
 
 	// log.Printf("SortNewsByDate - TimeFilter: %v, SortFilter: %s, Filtered items: %d\n",
@@ -106,19 +85,92 @@ func FilterNewsByLink(filteredItems []models.NewsItem, link string) []models.New
 	return filtered
 }
 
-func TruncateDescription(description template.HTML, maxLen int) template.HTML {
-	descStr := string(description)
-	if len(descStr) <= maxLen {
+// FilterNewsBySearch keeps the items whose title or description contains
+// query, ignoring case.
+func FilterNewsBySearch(filteredItems []models.NewsItem, query string) []models.NewsItem {
+	query = strings.ToLower(query)
+	filtered := make([]models.NewsItem, 0, len(filteredItems))
+	for _, item := range filteredItems {
+		if strings.Contains(strings.ToLower(item.Title), query) ||
+			strings.Contains(strings.ToLower(item.Description), query) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered
+}
+
+func FilterNewsByCategory(filteredItems []models.NewsItem, category string) []models.NewsItem {
+	filtered := make([]models.NewsItem, 0, len(filteredItems))
+	for _, item := range filteredItems {
+		if strings.EqualFold(item.Category, category) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered
+}
+
+// DedupeByID drops items whose ID was already seen, keeping the first.
+func DedupeByID(items []models.NewsItem) []models.NewsItem {
+	seen := make(map[string]bool, len(items))
+	deduped := make([]models.NewsItem, 0, len(items))
+	for _, item := range items {
+		if seen[item.ID] {
+			continue
+		}
+		seen[item.ID] = true
+		deduped = append(deduped, item)
+	}
+
+	return deduped
+}
+
+// FilterPodcasts keeps the items that carry an audio file.
+func FilterPodcasts(filteredItems []models.NewsItem) []models.NewsItem {
+	filtered := make([]models.NewsItem, 0, len(filteredItems))
+	for _, item := range filteredItems {
+		if strings.HasPrefix(item.MediaType, "audio/") {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered
+}
+
+// AveragePostInterval returns the mean time between consecutive items, or 0
+// when there are fewer than two items.
+func AveragePostInterval(items []models.NewsItem) time.Duration {
+	if len(items) < 2 {
+		return 0
+	}
+	oldest, newest := items[0].PubDate, items[0].PubDate
+	for _, item := range items[1:] {
+		if item.PubDate.Before(oldest) {
+			oldest = item.PubDate
+		}
+		if item.PubDate.After(newest) {
+			newest = item.PubDate
+		}
+	}
+	return newest.Sub(oldest) / time.Duration(len(items)-1)
+}
+
+// TruncateDescription shortens plain text to at most maxLen characters,
+// cutting at a word boundary.
+func TruncateDescription(description string, maxLen int) string {
+	runes := []rune(description)
+	if len(runes) <= maxLen {
 		return description
 	}
 
-	truncated := descStr[:maxLen]
+	truncated := string(runes[:maxLen])
 
 	if lastSpace := strings.LastIndex(truncated, " "); lastSpace > 0 {
 		truncated = truncated[:lastSpace]
 	}
 
-	return template.HTML(strings.TrimSpace(truncated) + " …")
+	return strings.TrimSpace(truncated) + " …"
 }
 
 func GetUniqueItems(items []models.NewsItem) UniqueItemsResult {
@@ -154,7 +206,10 @@ func GetUniqueItems(items []models.NewsItem) UniqueItemsResult {
 	}
 }
 
-var faviconCache = make(map[string]string)
+var (
+	faviconCache = make(map[string]string)
+	faviconMu    sync.Mutex
+)
 
 func GetFaviconURL(link string) string {
 	u, err := url.Parse(link)
@@ -164,7 +219,10 @@ func GetFaviconURL(link string) string {
 	host := u.Host
 
 	// Кеш
-	if cached, ok := faviconCache[host]; ok {
+	faviconMu.Lock()
+	cached, ok := faviconCache[host]
+	faviconMu.Unlock()
+	if ok {
 		return cached
 	}
 
@@ -186,7 +244,9 @@ func GetFaviconURL(link string) string {
 					if !iconURL.IsAbs() {
 						iconURL = u.ResolveReference(iconURL)
 					}
+					faviconMu.Lock()
 					faviconCache[host] = iconURL.String()
+					faviconMu.Unlock()
 					return iconURL.String()
 				}
 			}
@@ -195,6 +255,85 @@ func GetFaviconURL(link string) string {
 
 	// fallback на стандартный путь
 	defaultFavicon := fmt.Sprintf("%s/favicon.ico", home)
+	faviconMu.Lock()
 	faviconCache[host] = defaultFavicon
+	faviconMu.Unlock()
 	return defaultFavicon
 }
+
+// GetCategories counts items and sources per category, largest first.
+// Items without a category are not counted.
+func GetCategories(items []models.NewsItem) []CategoryCount {
+	index := make(map[string]int)
+	sources := make(map[string]map[string]bool)
+	var categories []CategoryCount
+	for _, item := range items {
+		if item.Category == "" {
+			continue
+		}
+		i, ok := index[item.Category]
+		if !ok {
+			i = len(categories)
+			index[item.Category] = i
+			sources[item.Category] = make(map[string]bool)
+			categories = append(categories, CategoryCount{Name: item.Category})
+		}
+		categories[i].Count++
+		sources[item.Category][item.ChannelLink] = true
+	}
+
+	for i := range categories {
+		categories[i].Sources = len(sources[categories[i].Name])
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].Count != categories[j].Count {
+			return categories[i].Count > categories[j].Count
+		}
+		return categories[i].Name < categories[j].Name
+	})
+	return categories
+}
+
+// GetSources lists the channels of items, busiest first.
+func GetSources(items []models.NewsItem) []Source {
+	uniqueItems := GetUniqueItems(items)
+	sources := make([]Source, 0, len(uniqueItems.Items))
+	for _, item := range uniqueItems.Items {
+		sources = append(sources, Source{
+			Title:    item.ChannelTitle,
+			Link:     item.ChannelLink,
+			Category: item.Category,
+			Favicon:  uniqueItems.FaviconURLs[item.ChannelLink],
+			FeedURL:  item.FeedURL,
+			Count:    uniqueItems.Counts[item.ChannelLink],
+		})
+	}
+	return sources
+}
+
+// GetCategoryTree groups the sources of items by category in the order of
+// GetCategories. Sources without a category come last, in a group with an
+// empty name.
+func GetCategoryTree(items []models.NewsItem) []CategoryGroup {
+	categories := GetCategories(items)
+	tree := make([]CategoryGroup, 0, len(categories)+1)
+	index := make(map[string]int, len(categories))
+	for _, c := range categories {
+		index[c.Name] = len(tree)
+		tree = append(tree, CategoryGroup{Name: c.Name, Count: c.Count})
+	}
+
+	for _, source := range GetSources(items) {
+		i, ok := index[source.Category]
+		if !ok {
+			i = len(tree)
+			index[source.Category] = i
+			tree = append(tree, CategoryGroup{Name: source.Category})
+		}
+		if source.Category == "" {
+			tree[i].Count += source.Count
+		}
+		tree[i].Sources = append(tree[i].Sources, source)
+	}
+	return tree
+}
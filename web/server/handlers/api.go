@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"news-aggregator/models"
+	"news-aggregator/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The /api/v1 endpoints are read-only and session-less: every filter comes
+// from the query string and, unlike the page, there is no time window
+// unless hours is given.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+type apiError struct {
+	Error apiErrorBody `json:"error"`
+}
+
+type apiErrorBody struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+type apiItemsPage struct {
+	Items      []any  `json:"items"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// itemFields are the names apiFields gives, the values accepted by the
+// fields parameter.
+var itemFields = func() map[string]bool {
+	fields := apiFields(models.NewsItem{})
+	names := make(map[string]bool, len(fields))
+	for name := range fields {
+		names[name] = true
+	}
+	return names
+}()
+
+// apiFields returns the JSON fields of item as the API shows them: the
+// episode duration is in whole seconds instead of time.Duration's
+// nanoseconds.
+func apiFields(item models.NewsItem) map[string]json.RawMessage {
+	var fields map[string]json.RawMessage
+	b, _ := json.Marshal(item)
+	json.Unmarshal(b, &fields)
+	delete(fields, "duration")
+	fields["durationSeconds"] = json.RawMessage(strconv.FormatInt(int64(item.Duration/time.Second), 10))
+	return fields
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("Error encoding JSON:", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, apiError{Error: apiErrorBody{Status: status, Message: message}})
+}
+
+// allowGet answers anything but GET and HEAD with 405.
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+	return false
+}
+
+// apiView reads the filters of an API request. Unlike the page it rejects
+// values it doesn't understand.
+func apiView(query url.Values) (viewState, error) {
+	if hours := query.Get("hours"); hours != "" {
+		if n, err := strconv.Atoi(hours); err != nil || n <= 0 || n > MaxTimeFilterHours {
+			return viewState{}, fmt.Errorf("hours must be between 1 and %d", MaxTimeFilterHours)
+		}
+	}
+	if sort := query.Get("sort"); sort != "" && sort != "asc" && sort != "desc" {
+		return viewState{}, fmt.Errorf("sort must be asc or desc")
+	}
+	return viewState{SortFilter: DefaultSortFilter}.withQuery(query), nil
+}
+
+// selectFields keeps only fields of item; all of them when fields is nil.
+func selectFields(item models.NewsItem, fields []string) any {
+	all := apiFields(item)
+	if fields == nil {
+		return all
+	}
+	selected := make(map[string]json.RawMessage, len(fields))
+	for _, name := range fields {
+		selected[name] = all[name]
+	}
+	return selected
+}
+
+func parseFields(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var fields []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if !itemFields[name] {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// HandleAPIItems serves GET /api/v1/items?q=&source=&category=&hours=&sort=
+// &podcasts=&limit=&cursor=&fields=
+func HandleAPIItems(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	view, err := apiView(query)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := DefaultPageSize
+	if s := query.Get("limit"); s != "" {
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > MaxPageSize {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", MaxPageSize))
+			return
+		}
+	}
+	fields, err := parseFields(query.Get("fields"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items := view.items()
+	page, next, err := utils.Page(items, query.Get("cursor"), limit, view.SortFilter)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result := apiItemsPage{Items: make([]any, 0, len(page)), Total: len(items), NextCursor: next}
+	for _, item := range page {
+		result.Items = append(result.Items, selectFields(item, fields))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// HandleAPIItem serves GET /api/v1/items/{id}?fields=
+func HandleAPIItem(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/items/")
+	item, ok := findItem(id)
+	if id == "" || !ok {
+		writeAPIError(w, http.StatusNotFound, "item not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, selectFields(item, fields))
+}
+
+// HandleAPISources serves GET /api/v1/sources?category=&hours=, busiest
+// source first.
+func HandleAPISources(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	view, err := apiView(r.URL.Query())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	items := view.windowItems()
+	if view.Category != "" {
+		items = utils.FilterNewsByCategory(items, view.Category)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"sources": utils.GetSources(items)})
+}
+
+// HandleAPICategories serves GET /api/v1/categories?hours=
+func HandleAPICategories(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	view, err := apiView(r.URL.Query())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	categories := utils.GetCategories(view.windowItems())
+	if categories == nil {
+		categories = []utils.CategoryCount{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"categories": categories})
+}
+
+// HandleAPINotFound answers unknown /api/v1 paths with a JSON error.
+func HandleAPINotFound(w http.ResponseWriter, r *http.Request) {
+	writeAPIError(w, http.StatusNotFound, "not found")
+}
@@ -19,63 +19,178 @@ import (
 )
 
 var (
-	newsItems    []models.NewsItem
-	filterItems  []models.NewsItem
-	timeFilter   time.Duration
-	sortFilter   string
-	channelTitle string
-	mu           sync.Mutex
-	sseClients   map[chan string]bool
-	feedsConfig  []config.FeedConfig
+	newsItems   []models.NewsItem
+	mu          sync.Mutex
+	sseClients  map[chan sseEvent]bool
+	feedsConfig []config.FeedConfig
 )
 
+const (
+	healthFile        = "data/health.json"
+	seenFile          = "data/seen.json"
+	fullTextFile      = "data/fulltext.json"
+	feedItemsTemplate = "web/templates/feed-items.html"
+)
+
+// FeedPageSize is how many stories the feed view renders at a time; the
+// page script loads the next ones on scroll.
+const FeedPageSize = 30
+
+var templateFuncs = template.FuncMap{
+	"truncate": utils.TruncateDescription,
+	"formatDate": func(t time.Time) string {
+		return t.Format("02.01.2006 15:04:05")
+	},
+	"formatDuration": func(d time.Duration) string {
+		d = d.Round(time.Second)
+		h, m, sec := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+		if h > 0 {
+			return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+		}
+		return fmt.Sprintf("%d:%02d", m, sec)
+	},
+	"mediaKind": func(mediaType string) string {
+		kind, _, _ := strings.Cut(mediaType, "/")
+		return kind
+	},
+}
+
+// newsTemplate parses text together with the shared "feed-items" template.
+func newsTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("news").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.ParseFiles(feedItemsTemplate)
+}
+
+type sseEvent struct {
+	Name string
+	Data string
+}
+
 func init() {
-	sseClients = make(map[chan string]bool)
+	sseClients = make(map[chan sseEvent]bool)
 }
 
-func broadcastUpdate() {
+func broadcast(event sseEvent) {
 	mu.Lock()
 	defer mu.Unlock()
 
 	for client := range sseClients {
 		select {
-		case client <- "update":
+		case client <- event:
 		default:
 		}
 	}
 }
+
+func broadcastUpdate() {
+	broadcast(sseEvent{Name: "update", Data: "update"})
+}
+
+func broadcastProgress(done, total int) {
+	broadcast(sseEvent{Name: "progress", Data: fmt.Sprintf("%d/%d", done, total)})
+}
 func UpdateNews() {
-	if timeFilter == 0 {
-		timeFilter = 24 * time.Hour
-	}
-	if sortFilter == "" {
-		sortFilter = "desc"
-	}
-	if channelTitle == "" {
-		channelTitle = fmt.Sprintf("All news for the last %d hours", int(timeFilter.Hours()))
-	}
 	cfg, err := config.LoadConfig("config/config.na")
 	if err != nil {
 		log.Println("Error loading config:", err)
 	}
+	mu.Lock()
 	feedsConfig = cfg.Feeds
+	mu.Unlock()
+	if cfg.DeadAfter > 0 {
+		fetcher.DeadAfter = cfg.DeadAfter
+	}
+	if cfg.Timezone != "" {
+		if loc, err := time.LoadLocation(cfg.Timezone); err != nil {
+			log.Println("Error loading timezone:", err)
+		} else {
+			utils.DefaultLocation = loc
+		}
+	}
+	if err := fetcher.LoadHealth(healthFile); err != nil {
+		log.Println("Error loading feed health:", err)
+	}
+	if err := fetcher.LoadSeen(seenFile); err != nil {
+		log.Println("Error loading first-seen times:", err)
+	}
+	if err := fetcher.LoadFullText(fullTextFile); err != nil {
+		log.Println("Error loading full-text articles:", err)
+	}
 
+	feedItems := make(map[string][]models.NewsItem)
 	for {
-		var newItems []models.NewsItem
+		now := time.Now()
+		var due []config.FeedConfig
+		for _, feed := range feedsConfig {
+			if fetcher.Due(feed, now) {
+				due = append(due, feed)
+			}
+		}
+
+		if len(due) > 0 {
+			results := fetcher.FetchAll(due, cfg.Workers, cfg.PerHost, func(feed config.FeedConfig, done, total int) {
+				log.Printf("Feed %d/%d: URL=%s, Category=%s", done, total, feed.URL, feed.Category)
+				broadcastProgress(done, total)
+			})
+			for i, feed := range due {
+				if fetcher.IsDead(feed.URL) {
+					delete(feedItems, feed.URL)
+					log.Printf("Feed %s is dead, skipping it until it is re-enabled", feed.URL)
+					continue
+				}
+				// A failing feed keeps showing its last good items until it
+				// is dead.
+				if health, _ := fetcher.Health(feed.URL); health.ConsecutiveFailures == 0 {
+					feedItems[feed.URL] = results[i]
+				}
+				next := fetcher.ScheduleNext(feed, time.Now())
+				log.Printf("Next fetch of %s at %s", feed.URL, next.Format(time.RFC3339))
+			}
+			if err := fetcher.SaveHealth(healthFile); err != nil {
+				log.Println("Error saving feed health:", err)
+			}
+			if err := fetcher.SaveSeen(seenFile); err != nil {
+				log.Println("Error saving first-seen times:", err)
+			}
+
+			// Feeds earlier in the config win when they share a story.
+			var newItems []models.NewsItem
+			for _, feed := range feedsConfig {
+				newItems = append(newItems, feedItems[feed.URL]...)
+			}
+			newItems = utils.DedupeByID(newItems)
+			// feedItems holds the items as fetched; put back the articles
+			// extracted since.
+			fetcher.FillFullText(newItems)
+			utils.AssignClusters(newItems)
 
-		for i, feed := range feedsConfig {
-			log.Printf("Feed %d: URL=%s, Category=%s", i+1, feed.URL, feed.Category)
-			news := fetcher.FetchNews(feed.URL, feed.Category)
-			newItems = append(newItems, news...)
 			mu.Lock()
 			newsItems = newItems
-			filterItems = utils.FilterNewsByTime(newsItems, timeFilter, sortFilter)
-			filterItems = utils.SortByDirection(filterItems, timeFilter, sortFilter)
 			mu.Unlock()
 			broadcastUpdate()
-			time.Sleep(1 * time.Second)
 		}
-		time.Sleep(30 * time.Minute)
+
+		// Article pages are fetched after the feeds are shown, so that slow
+		// sites don't hold the feeds back.
+		if fetcher.ExtractFullText() > 0 {
+			mu.Lock()
+			withText := append([]models.NewsItem(nil), newsItems...)
+			mu.Unlock()
+			fetcher.FillFullText(withText)
+			mu.Lock()
+			newsItems = withText
+			mu.Unlock()
+			broadcastUpdate()
+		}
+		if len(due) > 0 {
+			if err := fetcher.SaveFullText(fullTextFile); err != nil {
+				log.Println("Error saving full-text articles:", err)
+			}
+		}
+		time.Sleep(time.Minute)
 	}
 }
 
@@ -85,196 +200,129 @@ func HandleStaticFiles() {
 
 func HandleIndex(w http.ResponseWriter, r *http.Request) {
 	tmpl, err := template.New("index.html").
-		Funcs(template.FuncMap{
-			"truncate": func(html template.HTML, length int) string {
-				return string(utils.TruncateDescription(html, length))
-			},
-			"formatDate": func(t time.Time) string {
-				return t.Format("02.01.2006 15:04:05")
-			},
-		}).
-		ParseFiles("web/templates/index.html")
+		Funcs(templateFuncs).
+		ParseFiles("web/templates/index.html", feedItemsTemplate)
 	if err != nil {
 		log.Println("Error parsing template:", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
-	todayDate := time.Now().Format("02.01.2006")
-
-	mu.Lock()
-	defer mu.Unlock()
-
-	uniqueItems := utils.GetUniqueItems(filterItems)
-	//log.Printf("HandleIndex - mainitems: items %d, Filtered: %d", len(newsItems), len(filterItems))
-	if len(filterItems) == 0 {
-		err = tmpl.Execute(w, map[string]any{
-			"newsItems":       []models.NewsItem{},
-			"uniqueItems":     []models.NewsItem{},
-			"todayDate":       todayDate,
-			"totalCount":      0,
-			"loading":         true,
-			"timeFilterValue": timeFilter,
-			"sortFilter":      sortFilter,
-			"channelTitle":    channelTitle,
-		})
-	} else {
-		err = tmpl.Execute(w, map[string]any{
-			"newsItems":         filterItems,
-			"uniqueItems":       uniqueItems.Items,
-			"uniqueCounts":      uniqueItems.Counts,
-			"uniqueFaviconURLs": uniqueItems.FaviconURLs,
-			"todayDate":         todayDate,
-			"totalCount":        len(filterItems),
-			"loading":           false,
-			"timeFilterValue":   int(timeFilter.Hours()),
-			"sortFilter":        sortFilter,
-			"channelTitle":      channelTitle,
-		})
-	}
 
+	data, err := viewData(viewFromRequest(r), "")
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data["todayDate"] = time.Now().Format("02.01.2006")
+	//log.Printf("HandleIndex - Filtered: %d", data["totalCount"])
+	if err := tmpl.Execute(w, data); err != nil {
 		log.Println("Error rendering template:", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
-func HandleFilterNewsBySearch(w http.ResponseWriter, r *http.Request) {
-	queryEncoded := r.Header.Get("Search-Query")
-	query, err := url.QueryUnescape(queryEncoded)
+// viewData is everything the page shows for one page of view, starting
+// after cursor. The feed pages stories: storyCount counts them and
+// totalCount the articles they group. The sidebar lists the sources of the
+// whole time window so that one can switch between them; categoryTree holds
+// only what it shows of them, not whole items.
+func viewData(view viewState, cursor string) (map[string]any, error) {
+	filterItems := view.items()
+	stories := utils.GroupClusters(filterItems)
+	clusters, nextCursor, err := utils.PageClusters(stories, cursor, FeedPageSize, view.SortFilter)
 	if err != nil {
-		http.Error(w, "Failed to decode search query", http.StatusBadRequest)
-		return
+		return nil, err
 	}
-	if query == "" {
-		http.Error(w, "Search query is required", http.StatusBadRequest)
+	windowItems := view.windowItems()
+
+	return map[string]any{
+		"clusters":          clusters,
+		"nextCursor":        nextCursor,
+		"totalCount":        len(filterItems),
+		"storyCount":        len(stories),
+		"loading":           len(filterItems) == 0 && !view.filtered(),
+		"timeFilterValue":   int(view.TimeFilter.Hours()),
+		"sortFilter":        view.SortFilter,
+		"query":             view.Query,
+		"source":            view.Source,
+		"category":          view.Category,
+		"podcasts":          view.Podcasts,
+		"channelTitle":      view.title(filterItems),
+		"uniqueFaviconURLs": utils.GetUniqueItems(windowItems).FaviconURLs,
+		"categoryTree":      utils.GetCategoryTree(windowItems),
+		"deadFeeds":         collectDeadFeeds(),
+	}, nil
+}
+
+// writeView renders a page of view as the JSON the page script swaps in,
+// or appends when cursor is set.
+func writeView(w http.ResponseWriter, view viewState, cursor string) {
+	data, err := viewData(view, cursor)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	mu.Lock()
-	var filteredItems []models.NewsItem
-	for _, item := range filterItems {
-		if strings.Contains(strings.ToLower(item.Title), strings.ToLower(query)) ||
-			strings.Contains(strings.ToLower(string(item.Description)), strings.ToLower(query)) {
-			filteredItems = append(filteredItems, item)
-			filteredItems = append(filteredItems, item)
-		}
+	text := `{{ template "feed-view" . }}`
+	if cursor != "" {
+		text = `{{ template "story-clusters" .clusters }}`
 	}
-	mu.Unlock()
-	uniqueItems := utils.GetUniqueItems(filteredItems)
-
-	var tmpl *template.Template
-	if len(filteredItems) == 0 {
-		tmpl = template.Must(template.New("no-news").Parse(`
-        <div class="feed-item">
-            <h3>No news. Try changing the filter.</h3>
-        </div>
-        `))
-	} else {
-		tmpl = template.Must(template.New("news").
-			Funcs(template.FuncMap{
-				"truncate": func(html template.HTML, length int) string {
-					return string(utils.TruncateDescription(html, length))
-				},
-				"formatDate": func(t time.Time) string {
-					return t.Format("02.01.2006 15:04:05")
-				},
-			}).Parse(`
-            {{ range . }}
-            <div class="feed-item">
-                <h3 class="feed-title">{{.Title}}</h3>
-                <p class="feed-description">{{ truncate .Description 150 }}</p>
-                <span class="feed-info"><a href="{{.ItemLink}}" target="_blank">{{.ChannelTitle}}</a> <p>{{ formatDate .PubDate }}</p></span>
-             </div>
-            {{ end }}
-        `))
-	}
-
+	tmpl := template.Must(newsTemplate(text))
 	var feedViewHTML bytes.Buffer
-	err = tmpl.Execute(&feedViewHTML, filteredItems)
-	if err != nil {
-		log.Println("Error rendering filtered news:", err)
+	if err := tmpl.Execute(&feedViewHTML, data); err != nil {
+		log.Println("Error rendering news template:", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
+	delete(data, "clusters")
+	data["feedViewHTML"] = feedViewHTML.String()
 
-	response := map[string]interface{}{
-		"feedViewHTML":      feedViewHTML.String(),
-		"totalCount":        len(filteredItems),
-		"uniqueItems":       uniqueItems.Items,
-		"uniqueCounts":      uniqueItems.Counts,
-		"uniqueFaviconURLs": uniqueItems.FaviconURLs,
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Println("Error encoding JSON:", err)
 	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+// HandleFilterNewsBySearch renders the search view. The query comes from
+// the q parameter or the Search-Query header.
+func HandleFilterNewsBySearch(w http.ResponseWriter, r *http.Request) {
+	view := viewFromRequest(r)
+	if view.Query == "" {
+		query, err := url.QueryUnescape(r.Header.Get("Search-Query"))
+		if err != nil {
+			http.Error(w, "Failed to decode search query", http.StatusBadRequest)
+			return
+		}
+		view.Query = strings.TrimSpace(query)
+	}
+	if view.Query == "" {
+		http.Error(w, "Search query is required", http.StatusBadRequest)
+		return
+	}
+	writeView(w, view, r.URL.Query().Get("cursor"))
 }
 
 func HandleSortNews(w http.ResponseWriter, r *http.Request) {
-	mu.Lock()
-	defer mu.Unlock()
+	view := viewFromRequest(r)
 
 	hoursStr := r.Header.Get("timeFilter")
 	if hoursStr != "" {
 		hours, err := strconv.Atoi(hoursStr)
-		if err != nil {
+		if err != nil || hours <= 0 || hours > MaxTimeFilterHours {
 			hours = 24
 		}
-		timeFilter = time.Duration(hours) * time.Hour
+		view.TimeFilter = time.Duration(hours) * time.Hour
 	}
 
 	sortOrder := r.Header.Get("sortFilter")
 
 	if sortOrder == "asc" || sortOrder == "desc" {
-		sortFilter = sortOrder
-	}
-
-	filteredItems := utils.FilterNewsByTime(newsItems, timeFilter, sortFilter)
-	filteredItems = utils.SortByDirection(filteredItems, timeFilter, sortFilter)
-	filterItems = filteredItems
-	uniqueItems := utils.GetUniqueItems(filterItems)
-	//log.Printf("TimeFilter: %v, SortFilter: %s, Total news items: %d, Filtered items: %d\n",
-	//	timeFilter, sortFilter, len(newsItems), len(filterItems))
-
-	tmpl := template.Must(template.New("news").
-		Funcs(template.FuncMap{
-			"truncate": func(html template.HTML, length int) string {
-				return string(utils.TruncateDescription(html, length))
-			},
-			"formatDate": func(t time.Time) string {
-				return t.Format("02.01.2006 15:04:05")
-			},
-		}).Parse(`
-            {{ range .newsItems }}
-            <div class="feed-item">
-                <h3 class="feed-title">{{.Title}}</h3>
-                <p class="feed-description">{{ truncate .Description 150 }}</p>
-                <span class="feed-info"><a href="{{.ItemLink}}" target="_blank">{{.ChannelTitle}}</a> <p>{{ formatDate .PubDate }}</p></span>
-             </div>
-            {{ end }}
-    `))
-
-	var feedViewHTML bytes.Buffer
-	err := tmpl.Execute(&feedViewHTML, map[string]interface{}{
-		"newsItems": filterItems,
-	})
-	if err != nil {
-		log.Println("Error rendering feed-view template:", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
+		view.SortFilter = sortOrder
 	}
+	saveView(w, r, view)
+	//log.Printf("TimeFilter: %v, SortFilter: %s\n", view.TimeFilter, view.SortFilter)
 
-	response := map[string]interface{}{
-		"feedViewHTML":      feedViewHTML.String(),
-		"totalCount":        len(filterItems),
-		"timeFilterValue":   int(timeFilter.Hours()),
-		"sortFilter":        sortFilter,
-		"uniqueItems":       uniqueItems.Items,
-		"uniqueCounts":      uniqueItems.Counts,
-		"uniqueFaviconURLs": uniqueItems.FaviconURLs,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeView(w, view, r.URL.Query().Get("cursor"))
 }
 
 func HandleSSE(w http.ResponseWriter, r *http.Request) {
@@ -290,7 +338,7 @@ func HandleSSE(w http.ResponseWriter, r *http.Request) {
 
 	//lastEventID := r.Header.Get("Last-Event-ID")
 	//log.Printf("Last-Event-ID: %s", lastEventID)
-	messageChan := make(chan string)
+	messageChan := make(chan sseEvent)
 
 	mu.Lock()
 	sseClients[messageChan] = true
@@ -315,7 +363,7 @@ func HandleSSE(w http.ResponseWriter, r *http.Request) {
 		select {
 		case msg := <-messageChan:
 			eventID++
-			fmt.Fprintf(w, "id: %d\nevent: update\ndata: %s\n\n", eventID, msg)
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", eventID, msg.Name, msg.Data)
 
 			flusher.Flush()
 		case <-ticker.C:
@@ -327,121 +375,39 @@ func HandleSSE(w http.ResponseWriter, r *http.Request) {
 	}
 }
 func HandleLoadNews(w http.ResponseWriter, r *http.Request) {
-	mu.Lock()
-	defer mu.Unlock()
-
-	if timeFilter == 0 {
-		timeFilter = 24 * time.Hour
-	}
-	if sortFilter == "" {
-		sortFilter = "desc"
-	}
-	channelTitle = fmt.Sprintf("All news for the last %d hours", int(timeFilter.Hours()))
-
-	loading := len(filterItems) == 0
-	uniqueItems := utils.GetUniqueItems(filterItems)
-
-	tmpl := template.Must(template.New("news").Funcs(template.FuncMap{
-		"truncate": func(html template.HTML, length int) string {
-			return string(utils.TruncateDescription(html, length))
-		},
-		"formatDate": func(t time.Time) string {
-			return t.Format("02.01.2006 15:04:05")
-		},
-	}).Parse(`
-            {{ if .loading }}
-            <div id="loading" class="loading">
-                <h3>Loading...</h3>
-            </div>
-            {{ else }}
-            {{ range .newsItems }}
-            <div class="feed-item">
-                <h3 class="feed-title">{{.Title}}</h3>
-                <p class="feed-description">{{ truncate .Description 150 }}</p>
-                <span class="feed-info"><a href="{{.ItemLink}}" target="_blank">{{.ChannelTitle}}</a> <p>{{ formatDate .PubDate }}</p></span>
-             </div>
-            {{ end }}
-            {{ end }}
-    `))
-
-	var feedViewHTML bytes.Buffer
-	err := tmpl.Execute(&feedViewHTML, map[string]interface{}{
-		"newsItems": filterItems,
-		"loading":   loading,
-	})
-	if err != nil {
-		log.Println("Error rendering news template:", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-	//log.Printf("HandleLoadNews -mainitems: items %d, Filtered: %d", len(newsItems), len(filterItems))
-	response := map[string]interface{}{
-		"feedViewHTML":      feedViewHTML.String(),
-		"totalCount":        len(filterItems),
-		"timeFilterValue":   int(timeFilter.Hours()),
-		"sortFilter":        sortFilter,
-		"uniqueItems":       uniqueItems.Items,
-		"uniqueCounts":      uniqueItems.Counts,
-		"uniqueFaviconURLs": uniqueItems.FaviconURLs,
-	}
-	//log.Printf("HandleLoadNews - Filtered: items %d, uniqueCounts: %d", len(filterItems), len(uniqueСounts))
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeView(w, viewFromRequest(r), r.URL.Query().Get("cursor"))
 }
-func HandleFilterNewsByLink(w http.ResponseWriter, r *http.Request) {
-	mu.Lock()
-	defer mu.Unlock()
 
-	linkQuery := r.Header.Get("Link")
-	if linkQuery == "" {
+// HandleFilterNewsByLink renders the view of one source. The source comes
+// from the source parameter or the Link header.
+func HandleFilterNewsByLink(w http.ResponseWriter, r *http.Request) {
+	view := viewFromRequest(r)
+	if view.Source == "" {
+		view.Source = r.Header.Get("Link")
+	}
+	if view.Source == "" {
 		http.Error(w, "Link header is required", http.StatusBadRequest)
 		return
 	}
+	writeView(w, view, r.URL.Query().Get("cursor"))
+}
 
-	filteredByLink := utils.FilterNewsByLink(filterItems, linkQuery)
-
-	if len(filteredByLink) > 0 {
-		channelTitle = filteredByLink[0].ChannelTitle
-		log.Println("Channel Title:", channelTitle)
+// HandleFilterNewsByCategory renders the category view. The category comes
+// from the category parameter or the Category header.
+func HandleFilterNewsByCategory(w http.ResponseWriter, r *http.Request) {
+	view := viewFromRequest(r)
+	if view.Category == "" {
+		view.Category = r.Header.Get("Category")
 	}
-	uniqueItems := utils.GetUniqueItems(filteredByLink)
-
-	tmpl := template.Must(template.New("news").
-		Funcs(template.FuncMap{
-			"truncate": func(html template.HTML, length int) string {
-				return string(utils.TruncateDescription(html, length))
-			},
-			"formatDate": func(t time.Time) string {
-				return t.Format("02.01.2006 15:04:05")
-			},
-		}).Parse(`
-           {{ range . }}
-            <div class="feed-item">
-                <h3 class="feed-title">{{.Title}}</h3>
-                <p class="feed-description">{{ truncate .Description 150 }}</p>
-                <span class="feed-info"><a href="{{.ItemLink}}" target="_blank">{{.ChannelTitle}}</a> <p>{{ formatDate .PubDate }}</p></span>
-             </div>
-            {{ end }}
-    `))
-
-	var feedViewHTML bytes.Buffer
-	if err := tmpl.Execute(&feedViewHTML, filteredByLink); err != nil {
-		log.Println("Error rendering template:", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	if view.Category == "" {
+		http.Error(w, "Category is required", http.StatusBadRequest)
 		return
 	}
+	writeView(w, view, r.URL.Query().Get("cursor"))
+}
 
-	response := map[string]interface{}{
-		"feedViewHTML":      feedViewHTML.String(),
-		"totalCount":        len(filterItems),
-		"uniqueItems":       uniqueItems.Items,
-		"uniqueCounts":      uniqueItems.Counts,
-		"uniqueFaviconURLs": uniqueItems.FaviconURLs,
-		"channelTitle":      channelTitle,
-	}
-	//log.Printf("HandleSortNewsByLink - Filtered: items %d, uniqueCounts: %d", len(filterItems), len(uniqueItems.Counts))
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Println("Error encoding JSON:", err)
-	}
+func HandleFilterPodcasts(w http.ResponseWriter, r *http.Request) {
+	view := viewFromRequest(r)
+	view.Podcasts = true
+	writeView(w, view, r.URL.Query().Get("cursor"))
 }
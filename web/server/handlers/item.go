@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"news-aggregator/models"
+	"strings"
+	"time"
+)
+
+// findItem looks id up among all fetched items, not only the filtered view,
+// so reader links keep working after the filter changes.
+func findItem(id string) (models.NewsItem, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, item := range newsItems {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return models.NewsItem{}, false
+}
+
+func HandleItem(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/item/")
+	item, ok := findItem(id)
+	if id == "" || !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	tmpl, err := template.New("item.html").
+		Funcs(templateFuncs).
+		ParseFiles("web/templates/item.html")
+	if err != nil {
+		log.Println("Error parsing template:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	err = tmpl.Execute(w, map[string]any{
+		"item":      item,
+		"todayDate": time.Now().Format("02.01.2006"),
+	})
+	if err != nil {
+		log.Println("Error rendering template:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"news-aggregator/models"
+	"news-aggregator/utils"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTimeFilter = 24 * time.Hour
+	DefaultSortFilter = "desc"
+	// MaxTimeFilterHours, a year, bounds the hours parameter so that it
+	// can't overflow time.Duration.
+	MaxTimeFilterHours = 8760
+
+	sessionCookie = "na_session"
+	sessionMaxAge = 30 * 24 * time.Hour
+	maxSessions   = 10000
+)
+
+// viewState is what one client is looking at. The time window and sort
+// order are kept per session so that one person changing them doesn't
+// change them for everybody; the other filters come from the URL only.
+type viewState struct {
+	TimeFilter time.Duration
+	SortFilter string
+	Query      string
+	Source     string
+	Category   string
+	Podcasts   bool
+}
+
+type session struct {
+	view     viewState
+	lastUsed time.Time
+}
+
+var (
+	sessionsMu sync.Mutex
+	sessions   = make(map[string]*session)
+)
+
+// viewFor returns the view state of the client that sent r, or the
+// default view when it has no session. It never creates one, so crawlers
+// and one-off requests leave nothing behind.
+func viewFor(r *http.Request) viewState {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	if s := lookupSession(r); s != nil {
+		s.lastUsed = time.Now()
+		return s.view
+	}
+	return viewState{TimeFilter: DefaultTimeFilter, SortFilter: DefaultSortFilter}
+}
+
+// saveView stores the time window and sort order of view for the client
+// that sent r, starting a session when it has none.
+func saveView(w http.ResponseWriter, r *http.Request, view viewState) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	stored := viewState{TimeFilter: view.TimeFilter, SortFilter: view.SortFilter}
+	if s := lookupSession(r); s != nil {
+		s.view = stored
+		s.lastUsed = time.Now()
+		return
+	}
+
+	pruneSessions()
+	id := newSessionID()
+	sessions[id] = &session{view: stored, lastUsed: time.Now()}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(sessionMaxAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// viewFromRequest is the client's session view with the filters in the URL
+// applied on top, so that a copied link shows the same news:
+// /?q=…&source=…&category=…&hours=…&sort=…&podcasts=1
+func viewFromRequest(r *http.Request) viewState {
+	return viewFor(r).withQuery(r.URL.Query())
+}
+
+// withQuery applies the filters in query to v. Invalid hours and sort
+// values are ignored.
+func (v viewState) withQuery(query url.Values) viewState {
+	if hours, err := strconv.Atoi(query.Get("hours")); err == nil && hours > 0 && hours <= MaxTimeFilterHours {
+		v.TimeFilter = time.Duration(hours) * time.Hour
+	}
+	if sort := query.Get("sort"); sort == "asc" || sort == "desc" {
+		v.SortFilter = sort
+	}
+	v.Query = strings.TrimSpace(query.Get("q"))
+	v.Source = query.Get("source")
+	v.Category = query.Get("category")
+	v.Podcasts = query.Get("podcasts") != ""
+	return v
+}
+
+func lookupSession(r *http.Request) *session {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+	return sessions[cookie.Value]
+}
+
+// pruneSessions drops idle sessions and, when there are still
+// maxSessions, the least recently used one to make room for a new session.
+func pruneSessions() {
+	cutoff := time.Now().Add(-sessionMaxAge)
+	oldestID := ""
+	for id, s := range sessions {
+		if s.lastUsed.Before(cutoff) {
+			delete(sessions, id)
+		} else if oldestID == "" || s.lastUsed.Before(sessions[oldestID].lastUsed) {
+			oldestID = id
+		}
+	}
+	if len(sessions) >= maxSessions {
+		delete(sessions, oldestID)
+	}
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// windowItems filters and sorts the shared news snapshot by the time
+// window only, a zero window keeps every item; the sidebar lists its
+// sources. The snapshot itself is never modified.
+func (v viewState) windowItems() []models.NewsItem {
+	mu.Lock()
+	snapshot := newsItems
+	mu.Unlock()
+
+	var filtered []models.NewsItem
+	if v.TimeFilter > 0 {
+		filtered = utils.FilterNewsByTime(snapshot, v.TimeFilter, v.SortFilter)
+	} else {
+		filtered = append(filtered, snapshot...)
+	}
+	return utils.SortByDirection(filtered, v.TimeFilter, v.SortFilter)
+}
+
+// items applies every filter of the view.
+func (v viewState) items() []models.NewsItem {
+	items := v.windowItems()
+	if v.Category != "" {
+		items = utils.FilterNewsByCategory(items, v.Category)
+	}
+	if v.Source != "" {
+		items = utils.FilterNewsByLink(items, v.Source)
+	}
+	if v.Podcasts {
+		items = utils.FilterPodcasts(items)
+	}
+	if v.Query != "" {
+		items = utils.FilterNewsBySearch(items, v.Query)
+	}
+	return items
+}
+
+// filtered reports whether anything beyond the time window is filtered.
+func (v viewState) filtered() bool {
+	return v.Query != "" || v.Source != "" || v.Category != "" || v.Podcasts
+}
+
+func (v viewState) title(items []models.NewsItem) string {
+	switch {
+	case v.Query != "":
+		return fmt.Sprintf("Search results for \"%s\"", v.Query)
+	case v.Podcasts:
+		return "Podcasts"
+	case v.Source != "":
+		if len(items) > 0 {
+			return items[0].ChannelTitle
+		}
+		return v.Source
+	case v.Category != "":
+		return fmt.Sprintf("News in %s for the last %d hours", v.Category, int(v.TimeFilter.Hours()))
+	}
+	return fmt.Sprintf("All news for the last %d hours", int(v.TimeFilter.Hours()))
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"news-aggregator/fetcher"
+	"news-aggregator/models"
+	"news-aggregator/utils"
+	"time"
+)
+
+type deadFeed struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+func collectDeadFeeds() []deadFeed {
+	mu.Lock()
+	feeds := feedsConfig
+	mu.Unlock()
+
+	dead := []deadFeed{}
+	for _, feed := range feeds {
+		if !fetcher.IsDead(feed.URL) {
+			continue
+		}
+		title := feed.Title
+		if title == "" {
+			title = feed.URL
+		}
+		dead = append(dead, deadFeed{URL: feed.URL, Title: title})
+	}
+	return dead
+}
+
+type feedStatus struct {
+	URL                    string            `json:"url"`
+	Title                  string            `json:"title"`
+	Category               string            `json:"category"`
+	Enabled                bool              `json:"enabled"`
+	LastFetch              time.Time         `json:"lastFetch"`
+	LastSuccess            time.Time         `json:"lastSuccess"`
+	LastError              string            `json:"lastError,omitempty"`
+	LastErrorKind          fetcher.ErrorKind `json:"lastErrorKind,omitempty"`
+	ConsecutiveFailures    int               `json:"consecutiveFailures"`
+	Dead                   bool              `json:"dead"`
+	ResponseTimeMs         int64             `json:"responseTimeMs"`
+	ItemCount              int               `json:"itemCount"`
+	AvgPostIntervalMinutes float64           `json:"avgPostIntervalMinutes"`
+}
+
+// collectFeedStatus builds one status row per configured feed. ItemCount is
+// limited to window, the posting interval uses every item the feed
+// currently has.
+func collectFeedStatus(window time.Duration) []feedStatus {
+	mu.Lock()
+	feeds := feedsConfig
+	byFeed := make(map[string][]models.NewsItem)
+	for _, item := range newsItems {
+		byFeed[item.FeedURL] = append(byFeed[item.FeedURL], item)
+	}
+	mu.Unlock()
+
+	statuses := make([]feedStatus, 0, len(feeds))
+	for _, feed := range feeds {
+		health, _ := fetcher.Health(feed.URL)
+		items := byFeed[feed.URL]
+
+		title := feed.Title
+		if title == "" && len(items) > 0 {
+			title = items[0].ChannelTitle
+		}
+
+		statuses = append(statuses, feedStatus{
+			URL:                    feed.URL,
+			Title:                  title,
+			Category:               feed.Category,
+			Enabled:                feed.Enabled,
+			LastFetch:              health.LastAttempt,
+			LastSuccess:            health.LastSuccess,
+			LastError:              health.LastError,
+			LastErrorKind:          health.LastErrorKind,
+			ConsecutiveFailures:    health.ConsecutiveFailures,
+			Dead:                   health.Dead,
+			ResponseTimeMs:         health.ResponseTime.Milliseconds(),
+			ItemCount:              len(utils.FilterNewsByTime(items, window, "")),
+			AvgPostIntervalMinutes: utils.AveragePostInterval(items).Minutes(),
+		})
+	}
+	return statuses
+}
+
+func HandleFeedStatus(w http.ResponseWriter, r *http.Request) {
+	tmpl, err := template.New("status.html").
+		Funcs(template.FuncMap{
+			"formatDate": func(t time.Time) string {
+				if t.IsZero() {
+					return "never"
+				}
+				return t.Format("02.01.2006 15:04:05")
+			},
+			"formatInterval": func(minutes float64) string {
+				if minutes <= 0 {
+					return "—"
+				}
+				return time.Duration(minutes * float64(time.Minute)).Round(time.Minute).String()
+			},
+		}).
+		ParseFiles("web/templates/status.html")
+	if err != nil {
+		log.Println("Error parsing template:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	view := viewFor(r)
+	err = tmpl.Execute(w, map[string]any{
+		"feeds":           collectFeedStatus(view.TimeFilter),
+		"todayDate":       time.Now().Format("02.01.2006"),
+		"timeFilterValue": int(view.TimeFilter.Hours()),
+	})
+	if err != nil {
+		log.Println("Error rendering template:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func HandleFeedStatusJSON(w http.ResponseWriter, r *http.Request) {
+	view := viewFor(r)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(collectFeedStatus(view.TimeFilter)); err != nil {
+		log.Println("Error encoding JSON:", err)
+	}
+}
+
+// HandleEnableFeed re-enables a feed that was marked dead.
+func HandleEnableFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	feedURL := r.FormValue("url")
+	if feedURL == "" {
+		http.Error(w, "url is required", http.StatusBadRequest)
+		return
+	}
+
+	fetcher.Revive(feedURL)
+	if err := fetcher.SaveHealth(healthFile); err != nil {
+		log.Println("Error saving feed health:", err)
+	}
+	log.Println("Feed re-enabled:", feedURL)
+	http.Redirect(w, r, "/feeds/status", http.StatusSeeOther)
+}
@@ -16,7 +16,18 @@ func StartWebServer() {
 	http.HandleFunc("/load-news", handlers.HandleLoadNews)
 	http.HandleFunc("/filter-by-search", handlers.HandleFilterNewsBySearch)
 	http.HandleFunc("/filter-by-link", handlers.HandleFilterNewsByLink)
+	http.HandleFunc("/filter-by-category", handlers.HandleFilterNewsByCategory)
 	http.HandleFunc("/sort-news", handlers.HandleSortNews)
+	http.HandleFunc("/filter-podcasts", handlers.HandleFilterPodcasts)
+	http.HandleFunc("/item/", handlers.HandleItem)
+	http.HandleFunc("/feeds/status", handlers.HandleFeedStatus)
+	http.HandleFunc("/api/feeds/status", handlers.HandleFeedStatusJSON)
+	http.HandleFunc("/feeds/enable", handlers.HandleEnableFeed)
+	http.HandleFunc("/api/v1/", handlers.HandleAPINotFound)
+	http.HandleFunc("/api/v1/items", handlers.HandleAPIItems)
+	http.HandleFunc("/api/v1/items/", handlers.HandleAPIItem)
+	http.HandleFunc("/api/v1/sources", handlers.HandleAPISources)
+	http.HandleFunc("/api/v1/categories", handlers.HandleAPICategories)
 	log.Println("Server is running on http://localhost:8080")
 	log.Println("Debug pprof available at http://localhost:8080/debug/pprof/")
 	log.Fatal(http.ListenAndServe(":8080", nil))
@@ -6,6 +6,7 @@ const arrowUp = 'M11.354 23a1 1 0 0 1-2 0V3.004l-7.19 7.19A.854.854 0 0 1 .957 8
 const arrowDown = 'M9 1a1 1 0 0 1 2 0v19.996l7.19-7.19a.854.854 0 0 1 1.206 1.208L10.76 23.65a.998.998 0 0 1-1.464.06L.604 15.018A.854.854 0 1 1 1.81 13.81L9 21V1Z'
 const elementList = {
     showAllNews: document.getElementById('show-all-news'),
+    showPodcasts: document.getElementById('show-podcasts'),
     uniqueLink: document.querySelector('.unique-link-list'),
     themeToggle: document.querySelector('.theme-toggle input[type="checkbox"]'),
     feedView: document.querySelector('.feed-view'),
@@ -18,8 +19,6 @@ const elementList = {
 };
 const API_ENDPOINTS = {
     LOAD_NEWS:        '/load-news',
-    FILTER_BY_LINK:   '/filter-by-link',
-    FILTER_BY_SEARCH: '/filter-by-search',
     HOME_VIEW:        '/home-view',
     SETTING_VIEW:     '/setting-view',
     ADD_FEED:         '/add-feed',
@@ -32,12 +31,17 @@ const MESSAGES = {
     SSE_INIT: 'SSE connection established',
     SSE_PING: 'Ping event received',
     SSE_UPDATE: 'Received update event',
+    SSE_PROGRESS: 'Received progress event',
     SSS_ERROR: 'SSE error occurred',
     NETWORK_ERROR: 'Network response was not ok',
 }
 //start
 document.addEventListener('DOMContentLoaded', function() {
     setupSSE();
+    const collapsed = collapsedCategories();
+    document.querySelectorAll('.category-group').forEach((group) => {
+        group.open = !collapsed.includes(group.dataset.category);
+    });
     const currentTheme = localStorage.getItem('theme');
     if (currentTheme) {
         document.documentElement.setAttribute('data-theme', currentTheme);
@@ -64,13 +68,21 @@ function setupSSE() {
 
         eventSource.addEventListener('init', () => {
             console.log(MESSAGES.SSE_INIT);
-            loadAllNews();
+            loadView();
             reconnectAttempts = 0;
         });
 
         eventSource.addEventListener('update', () => {
             console.log(MESSAGES.SSE_UPDATE);
-            loadAllNews();
+            loadView();
+        });
+
+        eventSource.addEventListener('progress', (e) => {
+            console.log(MESSAGES.SSE_PROGRESS, e.data);
+            const loading = document.querySelector('#loading h3');
+            if (loading) {
+                loading.textContent = `Loading... ${e.data} feeds`;
+            }
         });
 
         eventSource.addEventListener('ping', () => {
@@ -120,236 +132,263 @@ elementList.themeToggle.addEventListener('change', function(e) {
         localStorage.setItem('theme', 'light');
     }
 })
+// the filters of the view live in the URL, so the address bar can be shared
+let viewParams = new URLSearchParams(window.location.search);
+function pushView(changes, replace = false) {
+    Object.entries(changes).forEach(([key, value]) => {
+        if (value === null || value === '') {
+            viewParams.delete(key);
+        } else {
+            viewParams.set(key, value);
+        }
+    });
+    const query = viewParams.toString();
+    const url = query ? `/?${query}` : '/';
+    if (replace) {
+        history.replaceState(null, '', url);
+    } else {
+        history.pushState(null, '', url);
+    }
+}
+function viewURL(endpoint) {
+    const query = viewParams.toString();
+    return query ? `${endpoint}?${query}` : endpoint;
+}
+window.addEventListener('popstate', () => {
+    viewParams = new URLSearchParams(window.location.search);
+    elementList.searchInput.value = viewParams.get('q') || '';
+    loadView();
+});
 // show all news
 elementList.showAllNews.addEventListener('click', function (e) {
     e.preventDefault();
-    loadAllNews();
+    elementList.searchInput.value = '';
+    pushView({ q: null, source: null, category: null, podcasts: null });
+    loadView();
+});
+// show podcasts
+elementList.showPodcasts.addEventListener('click', function (e) {
+    e.preventDefault();
+    pushView({ podcasts: 1, source: null });
+    loadView();
 });
 //load news
-async function loadAllNews() {
-    elementList.showAllNews.classList.add('active');
+async function loadView(endpoint = API_ENDPOINTS.LOAD_NEWS) {
+    const version = ++viewVersion;
     try {
-        const response = await fetch(API_ENDPOINTS.LOAD_NEWS)
+        const response = await fetch(viewURL(endpoint));
         if (!response.ok) {
             throw new Error(MESSAGES.NETWORK_ERROR);
         }
         const data = await response.json();
-        const timeFilterValue = data.timeFilterValue;
+        if (version !== viewVersion) {
+            return;
+        }
         elementList.feedView.innerHTML = data.feedViewHTML;
-        elementList.count.textContent = data.totalCount;
-        elementList.newTitle.innerHTML = '';
-
-        const faviconSpan = document.createElement('span');
-        faviconSpan.className = 'favicon';
-        faviconSpan.innerHTML = `<svg xmlns="http://www.w3.org/2000/svg" class="icon" fill="none" viewBox="0 0 24 24">
-        <path fill="currentColor" fill-rule="evenodd" d="M24 0H0v3h24V0Zm-6 7H0v3h18V7ZM0 14h18v3H0v-3Zm24 7H0v3h24v-3Z" clip-rule="evenodd"/>
-            </svg>`
-        elementList.newTitle.appendChild(faviconSpan);
-        elementList.newTitle.appendChild(document.createTextNode(`All news for the last ` + data.timeFilterValue + ` hours`));
+        elementList.feedView.scrollTop = 0;
+        nextCursor = data.nextCursor || '';
+        elementList.count.textContent = data.storyCount;
+        elementList.count.title = `${data.storyCount} stories, ${data.totalCount} articles`;
+        renderTitle(data);
         document.querySelectorAll('.filter-popup input[type="radio"]').forEach((radio) => {
-            radio.checked = parseInt(radio.value, 10) === timeFilterValue;
-        });
-        elementList.uniqueLink.innerHTML = '';
-        data.uniqueItems.forEach((item) => {
-            const link = document.createElement('a');
-            link.href = item.channelLink;
-            link.dataset.channel = item.channelLink;
-            const faviconSpan = document.createElement('span');
-            faviconSpan.className = 'favicon';
-            const faviconImg = document.createElement('img');
-            faviconImg.src = data.uniqueFaviconURLs[item.channelLink] || fallbackSvg;
-            faviconImg.alt = 'favicon';
-            faviconImg.onerror = () => {
-                faviconImg.remove();
-                faviconSpan.innerHTML = fallbackSvg;
-            };
-            faviconSpan.appendChild(faviconImg);
-            link.innerHTML = `${item.channelTitle} <div class="info"><span class="category">${item.category}</span> <span class="count">${data.uniqueCounts[item.channelLink]}</span></div>`;
-            link.prepend(faviconSpan);
-            elementList.uniqueLink.appendChild(link);
-            const svg = document.getElementById('sort-icon');
-            if (data.sortFilter === 'desc') {
-               svg.querySelector('path').setAttribute('d', arrowDown);
-            }
-            else {
-                svg.querySelector('path').setAttribute('d', arrowUp);
-            }
-            elementList.sortAscDesc.dataset.sort = data.sortFilter;
-    });
-}
-    catch (error) {
-        console.error('Error loadAllNews:', error);
-    }
-};
-//show unique
-elementList.uniqueLink.addEventListener('click', function(e) {
-    e.preventDefault();
-    const link = e.target.closest('a');
-    if (link) {
-        document.querySelectorAll('.unique-link-list a.active').forEach(item => {
-            item.classList.remove('active');
-        });
-
-        link.classList.add('active');
-        showUniqueNews(link);
-    }
-});
-async function showUniqueNews(link) {
-    const channelLink = link.dataset.channel || link.getAttribute('href');
-    elementList.showAllNews.classList.remove('active');
-    try {
-        const response = await fetch(API_ENDPOINTS.FILTER_BY_LINK, {
-            method: 'GET',
-            headers: {
-                'Link': channelLink
-            }
+            radio.checked = parseInt(radio.value, 10) === data.timeFilterValue;
         });
-        if (!response.ok) {
-            throw new Error(MESSAGES.NETWORK_ERROR);
-        }
-        const data = await response.json();
-        elementList.feedView.innerHTML = data.feedViewHTML;
-        elementList.count.textContent = data.totalCount;
-        elementList.newTitle.innerHTML = '';
+        const svg = document.getElementById('sort-icon');
+        svg.querySelector('path').setAttribute('d', data.sortFilter === 'desc' ? arrowDown : arrowUp);
+        elementList.sortAscDesc.dataset.sort = data.sortFilter;
+        elementList.showAllNews.classList.toggle('active', !data.query && !data.source && !data.category && !data.podcasts);
+        elementList.showPodcasts.classList.toggle('active', data.podcasts);
 
-        const faviconSpan = document.createElement('span');
-        faviconSpan.className = 'favicon';
-        const faviconImg = document.createElement('img');
-        faviconImg.src = data.uniqueFaviconURLs[channelLink] || fallbackSvg;
-        faviconImg.alt = 'favicon';
-        faviconImg.onerror = () => {
-            faviconImg.remove();
-            faviconSpan.innerHTML = fallbackSvg;
-        };
-        faviconSpan.appendChild(faviconImg);
-        elementList.newTitle.appendChild(faviconSpan);
-        elementList.newTitle.appendChild(document.createTextNode(data.channelTitle));
-        document.querySelectorAll('.unique-link-list a').forEach(anchor => {
-            if (anchor.classList.length === 0) {
-                anchor.removeAttribute('class');
-            }
-        });
+        renderCategoryTree(data);
+        renderDeadFeeds(data.deadFeeds);
+        loadMoreIfNeeded();
     }
     catch (error) {
-        console.error('Error loadAllNews:', error);
+        console.error('Error loadView:', error);
     }
 };
-//filter search
-elementList.searchInput.addEventListener('input', function(e) {
-    e.preventDefault();
-    const searchValue = elementList.searchInput.value.trim();
-    if (searchValue === '') {
-        loadAllNews();
+//infinite scroll
+let viewVersion = 0;
+let nextCursor = elementList.feedView.dataset.nextCursor || '';
+let loadingMore = false;
+function loadMoreIfNeeded() {
+    const view = elementList.feedView;
+    // the feed scrolls inside its panel on desktop and with the page on mobile
+    const left = Math.max(
+        view.scrollHeight - view.scrollTop - view.clientHeight,
+        view.getBoundingClientRect().bottom - window.innerHeight,
+    );
+    if (left < 800) {
+        loadMore();
+    }
+}
+async function loadMore() {
+    if (!nextCursor || loadingMore) {
         return;
     }
-    filterNewsBySearch(searchValue);
-});
-async function filterNewsBySearch(searchValue) {
+    loadingMore = true;
+    const version = viewVersion;
+    const params = new URLSearchParams(viewParams);
+    params.set('cursor', nextCursor);
     try {
-        const response = await fetch(API_ENDPOINTS.FILTER_BY_SEARCH, {
-            method: 'POST',
-            headers: {
-                'Content-Type': 'application/json',
-                'Search-Query': encodeURIComponent(searchValue),
-            },
-        });
+        const response = await fetch(`${API_ENDPOINTS.LOAD_NEWS}?${params}`);
         if (!response.ok) {
             throw new Error(MESSAGES.NETWORK_ERROR);
         }
         const data = await response.json();
-        elementList.feedView.innerHTML = data.feedViewHTML;
-        elementList.count.textContent = data.totalCount;
-        elementList.newTitle.textContent = `Search results for "${searchValue}"`;
+        if (version !== viewVersion) {
+            return;
+        }
+        elementList.feedView.insertAdjacentHTML('beforeend', data.feedViewHTML);
+        nextCursor = data.nextCursor || '';
     }
     catch (error) {
-        console.error('Error filtering news by filterNewsBySearch', error);
+        console.error('Error loadMore:', error);
+        return;
     }
-};
- //filter time
-elementList.sortTime.forEach(radio => {
-    radio.addEventListener('change', function() {
-        const selectRadioValue = this.value
-        filterNewsByTime(selectRadioValue);
-    });
-});
-async function filterNewsByTime(timeValue) {
-    try {
-        const response = await fetch(API_ENDPOINTS.SORT_NEWS, {
-            method: 'GET',
-            headers: {
-                'timeFilter': timeValue,
-            },
-        });
-        if (!response.ok) {
-            throw new Error(MESSAGES.NETWORK_ERROR);
+    finally {
+        loadingMore = false;
+    }
+    loadMoreIfNeeded();
+}
+elementList.feedView.addEventListener('scroll', loadMoreIfNeeded, { passive: true });
+window.addEventListener('scroll', loadMoreIfNeeded, { passive: true });
+//category tree
+function collapsedCategories() {
+    return JSON.parse(localStorage.getItem('collapsedCategories') || '[]');
+}
+function renderCategoryTree(data) {
+    const collapsed = collapsedCategories();
+    elementList.uniqueLink.innerHTML = '';
+    (data.categoryTree || []).forEach((group) => {
+        const details = document.createElement('details');
+        details.className = 'category-group';
+        details.dataset.category = group.name;
+        details.open = !collapsed.includes(group.name);
+        const summary = document.createElement('summary');
+        const header = document.createElement(group.name ? 'a' : 'span');
+        header.className = 'category-link';
+        if (group.name) {
+            header.href = `/?category=${encodeURIComponent(group.name)}`;
+            header.dataset.category = group.name;
+            header.classList.toggle('active', group.name === data.category);
         }
-        const data = await response.json();
-        elementList.feedView.innerHTML = data.feedViewHTML;
-        elementList.count.textContent = data.totalCount;
-        elementList.newTitle.innerHTML = '';
+        const name = document.createElement('p');
+        name.textContent = group.name || 'Other';
+        header.appendChild(name);
+        header.insertAdjacentHTML('beforeend', `<div class="info"><span class="count">${group.count}</span></div>`);
+        summary.appendChild(header);
+        details.appendChild(summary);
+        group.sources.forEach((source) => {
+            const link = document.createElement('a');
+            link.href = `/?source=${encodeURIComponent(source.link)}`;
+            link.dataset.channel = source.link;
+            if (source.link === data.source) {
+                link.className = 'active';
+            }
+            const title = document.createElement('p');
+            title.textContent = source.title;
+            link.append(faviconFor(source.favicon), title);
+            link.insertAdjacentHTML('beforeend', `<div class="info"><span class="count">${source.count}</span></div>`);
+            details.appendChild(link);
+        });
+        elementList.uniqueLink.appendChild(details);
+    });
+}
+// remember which categories are collapsed; toggle doesn't bubble
+elementList.uniqueLink.addEventListener('toggle', function(e) {
+    const category = e.target.dataset.category;
+    if (category === undefined) {
+        return;
+    }
+    const collapsed = collapsedCategories().filter(name => name !== category);
+    if (!e.target.open) {
+        collapsed.push(category);
+    }
+    localStorage.setItem('collapsedCategories', JSON.stringify(collapsed));
+}, true);
+function renderTitle(data) {
+    elementList.newTitle.innerHTML = '';
+    if (data.query || data.podcasts) {
+        elementList.newTitle.textContent = data.channelTitle;
+        return;
+    }
+    if (data.source) {
+        elementList.newTitle.appendChild(faviconFor(data.uniqueFaviconURLs[data.source]));
+    } else {
         const faviconSpan = document.createElement('span');
         faviconSpan.className = 'favicon';
         faviconSpan.innerHTML = `<svg xmlns="http://www.w3.org/2000/svg" class="icon" fill="none" viewBox="0 0 24 24">
         <path fill="currentColor" fill-rule="evenodd" d="M24 0H0v3h24V0Zm-6 7H0v3h18V7ZM0 14h18v3H0v-3Zm24 7H0v3h24v-3Z" clip-rule="evenodd"/>
             </svg>`
         elementList.newTitle.appendChild(faviconSpan);
-        elementList.newTitle.appendChild(document.createTextNode(`All news for the last ` + data.timeFilterValue + ` hours`));
-        elementList.uniqueLink.innerHTML = '';
-        data.uniqueItems.forEach((item) => {
-            const link = document.createElement('a');
-            link.href = item.channelLink;
-            link.target = '_blank';
-            const faviconSpan = document.createElement('span');
-            faviconSpan.className = 'favicon';
-            const faviconImg = document.createElement('img');
-            faviconImg.src = data.uniqueFaviconURLs[item.channelLink] || fallbackSvg;
-            faviconImg.alt = 'favicon';
-            faviconImg.onerror = () => {
-                faviconImg.remove();
-                faviconSpan.innerHTML = fallbackSvg;
-            };
-            faviconSpan.appendChild(faviconImg);
-            link.innerHTML = `${item.channelTitle} <div class="info"><span class="category">${item.category}</span> <span class="count">${data.uniqueCounts[item.channelLink]}</span></div>`;
-            link.prepend(faviconSpan);
-            elementList.uniqueLink.appendChild(link);
-
+    }
+    elementList.newTitle.appendChild(document.createTextNode(data.channelTitle));
+}
+function faviconFor(src) {
+    const faviconSpan = document.createElement('span');
+    faviconSpan.className = 'favicon';
+    if (!src) {
+        faviconSpan.innerHTML = fallbackSvg;
+        return faviconSpan;
+    }
+    const faviconImg = document.createElement('img');
+    faviconImg.src = src;
+    faviconImg.alt = 'favicon';
+    faviconImg.onerror = () => {
+        faviconImg.remove();
+        faviconSpan.innerHTML = fallbackSvg;
+    };
+    faviconSpan.appendChild(faviconImg);
+    return faviconSpan;
+}
+//dead feeds
+function renderDeadFeeds(deadFeeds) {
+    (deadFeeds || []).forEach((feed) => {
+        const link = document.createElement('a');
+        link.href = '/feeds/status';
+        link.className = 'dead-feed';
+        link.title = feed.url;
+        const title = document.createElement('p');
+        title.textContent = feed.title;
+        link.appendChild(title);
+        link.insertAdjacentHTML('beforeend', `<div class="info"><span class="category">dead</span></div>`);
+        elementList.uniqueLink.appendChild(link);
     });
 }
-    catch (error) {
-        console.error('Error filtering news by filterNewsByTime', error);
+//show unique
+elementList.uniqueLink.addEventListener('click', function(e) {
+    const link = e.target.closest('a');
+    if (!link || link.classList.contains('dead-feed') || !link.dataset.channel && !link.dataset.category) {
+        return;
     }
-};
+    e.preventDefault();
+    elementList.searchInput.value = '';
+    if (link.dataset.category) {
+        pushView({ category: link.dataset.category, source: null, q: null, podcasts: null });
+    } else {
+        pushView({ source: link.dataset.channel, category: null, q: null, podcasts: null });
+    }
+    loadView();
+});
+//filter search
+elementList.searchInput.addEventListener('input', function(e) {
+    e.preventDefault();
+    // one history entry per search, not per keystroke
+    pushView({ q: elementList.searchInput.value.trim() }, viewParams.has('q'));
+    loadView();
+});
+ //filter time
+elementList.sortTime.forEach(radio => {
+    radio.addEventListener('change', function() {
+        pushView({ hours: this.value });
+        loadView(API_ENDPOINTS.SORT_NEWS);
+    });
+});
 //filter asc/desc
 elementList.sortAscDesc.addEventListener('click', function(e) {
     e.preventDefault();
-    filterNewsByAscDesc();
+    const newSort = elementList.sortAscDesc.dataset.sort === 'desc' ? 'asc' : 'desc';
+    pushView({ sort: newSort });
+    loadView(API_ENDPOINTS.SORT_NEWS);
 });
-async function filterNewsByAscDesc() {
-    const currentSort = elementList.sortAscDesc.dataset.sort;
-    const newSort = currentSort === 'desc' ? 'asc' : 'desc';
-    try {
-        const response = await fetch(API_ENDPOINTS.SORT_NEWS, {
-            method: 'GET',
-            headers: {
-                'sortFilter': newSort,
-            },
-        });
-        if (!response.ok) {
-            throw new Error(MESSAGES.NETWORK_ERROR);
-        }
-        const data = await response.json();
-        elementList.feedView.innerHTML = data.feedViewHTML;
-        elementList.count.textContent = data.totalCount;
-        const svg = document.getElementById('sort-icon');
-        if (newSort === 'desc') {
-           svg.querySelector('path').setAttribute('d', arrowDown);
-        }
-        else {
-            svg.querySelector('path').setAttribute('d', arrowUp);
-        }
-        elementList.sortAscDesc.dataset.sort = newSort;
-    }
-    catch (error) {
-        console.error('Error filtering news by filterNewsByAscDesc', error);
-    }
-};